@@ -1,7 +1,11 @@
 package goquery
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -10,15 +14,34 @@ type (
 		Select(columns ...string) Builder
 		Count(column string) Builder
 		Save(entities interface{}) Builder
-		Delete() Builder
+		Update() Builder
+		Set(column string, value interface{}) Builder
+		SetExpr(column, expr string, parameters ...interface{}) Builder
+		SetMap(values map[string]interface{}) Builder
+		Find(ids ...interface{}) Builder
+		Delete(entities ...interface{}) Builder
+		ForceDelete(entities ...interface{}) Builder
+		Restore(entities ...interface{}) Builder
+		WithTrashed() Builder
+		OnlyTrashed() Builder
+		OnConflict(columns ...string) Builder
+		DoUpdate(columns ...string) Builder
+		DoNothing() Builder
+		Returning(columns ...string) Builder
 		Distinct(bool) Builder
 		From(from string) Builder
-		Where(where string) Builder
-		AndWhere(where string) Builder
-		OrWhere(where string) Builder
-		Having(having string) Builder
-		AndHaving(having string) Builder
-		OrHaving(having string) Builder
+		Alias(alias string) Builder
+		Join(table interface{}, alias string, on interface{}, parameters ...interface{}) Builder
+		LeftJoin(table interface{}, alias string, on interface{}, parameters ...interface{}) Builder
+		RightJoin(table interface{}, alias string, on interface{}, parameters ...interface{}) Builder
+		FullJoin(table interface{}, alias string, on interface{}, parameters ...interface{}) Builder
+		CrossJoin(table interface{}, alias string) Builder
+		Where(where interface{}, parameters ...interface{}) Builder
+		AndWhere(where interface{}, parameters ...interface{}) Builder
+		OrWhere(where interface{}, parameters ...interface{}) Builder
+		Having(having interface{}, parameters ...interface{}) Builder
+		AndHaving(having interface{}, parameters ...interface{}) Builder
+		OrHaving(having interface{}, parameters ...interface{}) Builder
 		OrderBy(column, order string) Builder
 		AddOrderBy(column, order string) Builder
 		GroupBy(columns ...string) Builder
@@ -27,36 +50,103 @@ type (
 		Offset(i int64) Builder
 		SetParameters(parameters ...interface{}) Builder
 		AddParameters(parameters ...interface{}) Builder
+		SetNamedParameters(parameters interface{}) Builder
+		AddNamedParameters(parameters interface{}) Builder
+		SetDialect(dialect Dialect) Builder
+		OnUnknownColumns(policy ScanPolicy) Builder
+		OnMissingColumns(policy ScanPolicy) Builder
+		OnEmptySlice(policy EmptySlicePolicy) Builder
+		Track(tracker *Tracker) Builder
 		Reset() Builder
 		GetQuery() Query
 	}
 
 	builder struct {
-		t          reflect.Type
-		v          interface{}
-		statement  string
-		columns    []string
-		column     string
-		distinct   bool
-		from       string
-		where      string
-		orWhere    []string
-		andWhere   []string
-		having     string
-		orHaving   []string
-		andHaving  []string
-		order      map[string]string
-		groupby    []string
-		limit      int64
-		offset     int64
-		parameters []interface{}
+		t              reflect.Type
+		dialect        Dialect
+		unknownColumns ScanPolicy
+		missingColumns ScanPolicy
+		emptySlices    EmptySlicePolicy
+		tracker        *Tracker
+		v              interface{}
+		statement      string
+		upsert         *upsert
+		force          bool
+		trashed        trashedScope
+		returning      []string
+		sets           []assignment
+		columns        []string
+		column         string
+		distinct       bool
+		from           string
+		alias          string
+		joins          []join
+		keys           Condition
+		where          []clause
+		having         []clause
+		order          []ordering
+		groupby        []string
+		limit          int64
+		offset         int64
+		parameters     []interface{}
+		named          map[string]namedParameter
+		err            error
 
 		orderMu sync.RWMutex
 	}
 
+	join struct {
+		kind  string
+		table string
+		alias string
+		on    Condition
+	}
+
+	ordering struct {
+		expr      string
+		direction string
+		nulls     string
+	}
+
+	clause struct {
+		operator  string
+		condition Condition
+	}
+
+	assignment struct {
+		column string
+		value  Condition
+	}
+
+	upsert struct {
+		target  []string
+		update  []string
+		nothing bool
+	}
+
+	namedParameter struct {
+		value    interface{}
+		optional bool
+	}
+
+	EmptySlicePolicy int
+
+	trashedScope int
+
 	factory func(t reflect.Type) Builder
 )
 
+const (
+	EmptySliceFalse EmptySlicePolicy = iota
+	EmptySliceError
+)
+
+const (
+	withoutTrashed trashedScope = iota
+	withTrashed
+	onlyTrashed
+)
+
 var New factory
 
 func (b *builder) Select(columns ...string) Builder {
@@ -89,8 +179,153 @@ func (b *builder) Save(entities interface{}) Builder {
 	return b
 }
 
-func (b *builder) Delete() Builder {
+func (b *builder) Update() Builder {
+	b.statement = "update"
+
+	return b
+}
+
+func (b *builder) Set(column string, value interface{}) Builder {
+	b.assign(column, Expr("?", value))
+
+	return b
+}
+
+func (b *builder) SetExpr(column, expr string, parameters ...interface{}) Builder {
+	if len(parameters) > 0 {
+		b.assign(column, b.expr(expr, parameters))
+	} else {
+		b.assign(column, raw(expr))
+	}
+
+	return b
+}
+
+func (b *builder) SetMap(values map[string]interface{}) Builder {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		b.assign(column, Expr("?", values[column]))
+	}
+
+	return b
+}
+
+func (b *builder) Find(ids ...interface{}) Builder {
+	b.statement = "select"
+	b.columns = nil
+
+	m := getMetadata(b.t)
+	switch {
+	case len(m.pk) == 0:
+		b.setErr(fmt.Errorf("builder: No primary key for %s!", b.t.Name()))
+	case len(ids) != len(m.pk):
+		b.setErr(fmt.Errorf("builder: Expected %d primary key values for %s, got %d!", len(m.pk), b.t.Name(), len(ids)))
+	default:
+		b.keys = keyCondition(m.keys, [][]interface{}{ids})
+	}
+
+	return b
+}
+
+func (b *builder) Delete(entities ...interface{}) Builder {
 	b.statement = "delete"
+	b.force = false
+	b.keys = nil
+	if len(entities) == 0 {
+		return b
+	}
+
+	m := getMetadata(b.t)
+	if len(m.pk) == 0 {
+		b.setErr(fmt.Errorf("builder: No primary key for %s!", b.t.Name()))
+		return b
+	}
+
+	var values [][]interface{}
+	for _, entity := range entities {
+		v := reflect.Indirect(reflect.ValueOf(entity))
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			for i := 0; i < v.Len(); i++ {
+				values = b.entityKeys(values, reflect.Indirect(v.Index(i)), m)
+			}
+		} else {
+			values = b.entityKeys(values, v, m)
+		}
+	}
+	if len(values) > 0 {
+		b.keys = keyCondition(m.keys, values)
+	} else {
+		b.keys = raw("1=0")
+	}
+
+	return b
+}
+
+func (b *builder) ForceDelete(entities ...interface{}) Builder {
+	b.Delete(entities...)
+	b.force = true
+
+	return b
+}
+
+func (b *builder) Restore(entities ...interface{}) Builder {
+	b.Delete(entities...)
+	b.statement = "restore"
+	if getMetadata(b.t).softDelete < 0 {
+		b.setErr(fmt.Errorf("builder: No soft delete column for %s!", b.t.Name()))
+	}
+
+	return b
+}
+
+func (b *builder) WithTrashed() Builder {
+	b.trashed = withTrashed
+
+	return b
+}
+
+func (b *builder) OnlyTrashed() Builder {
+	b.trashed = onlyTrashed
+
+	return b
+}
+
+func (b *builder) OnConflict(columns ...string) Builder {
+	if b.upsert == nil {
+		b.upsert = &upsert{}
+	}
+	b.upsert.target = columns
+
+	return b
+}
+
+func (b *builder) DoUpdate(columns ...string) Builder {
+	if b.upsert == nil {
+		b.upsert = &upsert{}
+	}
+	b.upsert.update = columns
+	b.upsert.nothing = false
+
+	return b
+}
+
+func (b *builder) DoNothing() Builder {
+	if b.upsert == nil {
+		b.upsert = &upsert{}
+	}
+	b.upsert.update = nil
+	b.upsert.nothing = true
+
+	return b
+}
+
+func (b *builder) Returning(columns ...string) Builder {
+	b.returning = columns
 
 	return b
 }
@@ -101,42 +336,64 @@ func (b *builder) From(from string) Builder {
 	return b
 }
 
-func (b *builder) Where(where string) Builder {
-	b.andWhere = b.andWhere[:0]
-	b.orWhere = b.orWhere[:0]
-	b.where = where
+func (b *builder) Alias(alias string) Builder {
+	b.alias = alias
+
+	return b
+}
+
+func (b *builder) Join(table interface{}, alias string, on interface{}, parameters ...interface{}) Builder {
+	return b.join("JOIN", table, alias, on, parameters)
+}
+
+func (b *builder) LeftJoin(table interface{}, alias string, on interface{}, parameters ...interface{}) Builder {
+	return b.join("LEFT JOIN", table, alias, on, parameters)
+}
+
+func (b *builder) RightJoin(table interface{}, alias string, on interface{}, parameters ...interface{}) Builder {
+	return b.join("RIGHT JOIN", table, alias, on, parameters)
+}
+
+func (b *builder) FullJoin(table interface{}, alias string, on interface{}, parameters ...interface{}) Builder {
+	return b.join("FULL JOIN", table, alias, on, parameters)
+}
+
+func (b *builder) CrossJoin(table interface{}, alias string) Builder {
+	return b.join("CROSS JOIN", table, alias, nil, nil)
+}
+
+func (b *builder) Where(where interface{}, parameters ...interface{}) Builder {
+	b.where = b.clause(b.where[:0], "", where, parameters)
 
 	return b
 }
 
-func (b *builder) AndWhere(where string) Builder {
-	b.andWhere = append(b.andWhere, where)
+func (b *builder) AndWhere(where interface{}, parameters ...interface{}) Builder {
+	b.where = b.clause(b.where, "AND", where, parameters)
 
 	return b
 }
 
-func (b *builder) OrWhere(where string) Builder {
-	b.orWhere = append(b.orWhere, where)
+func (b *builder) OrWhere(where interface{}, parameters ...interface{}) Builder {
+	b.where = b.clause(b.where, "OR", where, parameters)
 
 	return b
 }
 
-func (b *builder) Having(having string) Builder {
-	b.andHaving = b.andHaving[:0]
-	b.orHaving = b.orHaving[:0]
-	b.having = having
+func (b *builder) Having(having interface{}, parameters ...interface{}) Builder {
+	b.having = b.clause(b.having[:0], "", having, parameters)
 
 	return b
 }
 
-func (b *builder) AndHaving(having string) Builder {
-	b.andHaving = append(b.andHaving, having)
+func (b *builder) AndHaving(having interface{}, parameters ...interface{}) Builder {
+	b.having = b.clause(b.having, "AND", having, parameters)
 
 	return b
 }
 
-func (b *builder) OrHaving(having string) Builder {
-	b.orHaving = append(b.orHaving, having)
+func (b *builder) OrHaving(having interface{}, parameters ...interface{}) Builder {
+	b.having = b.clause(b.having, "OR", having, parameters)
 
 	return b
 }
@@ -144,8 +401,7 @@ func (b *builder) OrHaving(having string) Builder {
 func (b *builder) OrderBy(column, order string) Builder {
 	b.orderMu.Lock()
 	defer b.orderMu.Unlock()
-	b.order = make(map[string]string)
-	b.order[column] = order
+	b.order = b.addOrder(b.order[:0], column, order)
 
 	return b
 }
@@ -153,7 +409,7 @@ func (b *builder) OrderBy(column, order string) Builder {
 func (b *builder) AddOrderBy(column, order string) Builder {
 	b.orderMu.Lock()
 	defer b.orderMu.Unlock()
-	b.order[column] = order
+	b.order = b.addOrder(b.order, column, order)
 
 	return b
 }
@@ -196,46 +452,119 @@ func (b *builder) AddParameters(parameters ...interface{}) Builder {
 	return b
 }
 
+func (b *builder) SetNamedParameters(parameters interface{}) Builder {
+	b.named = nil
+
+	return b.AddNamedParameters(parameters)
+}
+
+func (b *builder) AddNamedParameters(parameters interface{}) Builder {
+	if b.named == nil {
+		b.named = make(map[string]namedParameter)
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(parameters))
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for _, key := range v.MapKeys() {
+			b.named[key.String()] = namedParameter{v.MapIndex(key).Interface(), false}
+		}
+	case v.Kind() == reflect.Struct:
+		for _, f := range getMetadata(v.Type()).fields {
+			b.named[f.column] = namedParameter{v.Field(f.index).Interface(), true}
+		}
+	default:
+		b.setErr(fmt.Errorf("builder: Unsupported named parameters type %T!", parameters))
+	}
+
+	return b
+}
+
+func (b *builder) SetDialect(dialect Dialect) Builder {
+	if dialect == nil {
+		dialect = DefaultDialect
+	}
+	b.dialect = dialect
+
+	return b
+}
+
+func (b *builder) OnUnknownColumns(policy ScanPolicy) Builder {
+	b.unknownColumns = policy
+
+	return b
+}
+
+func (b *builder) OnMissingColumns(policy ScanPolicy) Builder {
+	b.missingColumns = policy
+
+	return b
+}
+
+func (b *builder) OnEmptySlice(policy EmptySlicePolicy) Builder {
+	b.emptySlices = policy
+
+	return b
+}
+
+func (b *builder) Track(tracker *Tracker) Builder {
+	b.tracker = tracker
+
+	return b
+}
+
 func (b *builder) Reset() Builder {
 	var (
 		v          interface{}
 		statement  string
+		upsert     *upsert
+		force      bool
+		trashed    trashedScope
+		returning  []string
+		sets       []assignment
 		columns    []string
 		column     string
 		distinct   bool
 		from       string
-		where      string
-		orWhere    []string
-		andWhere   []string
-		having     string
-		orHaving   []string
-		andHaving  []string
-		order      map[string]string
+		alias      string
+		joins      []join
+		keys       Condition
+		where      []clause
+		having     []clause
+		order      []ordering
 		groupby    []string
 		limit      int64
 		offset     int64
 		parameters []interface{}
+		named      map[string]namedParameter
+		err        error
 	)
 	b.orderMu.Lock()
 	defer b.orderMu.Unlock()
 
 	b.v = v
 	b.statement = statement
+	b.upsert = upsert
+	b.force = force
+	b.trashed = trashed
+	b.returning = returning
+	b.sets = sets
 	b.columns = columns
 	b.column = column
 	b.distinct = distinct
 	b.from = from
+	b.alias = alias
+	b.joins = joins
+	b.keys = keys
 	b.where = where
-	b.orWhere = orWhere
-	b.andWhere = andWhere
 	b.having = having
-	b.orHaving = orHaving
-	b.andHaving = andHaving
 	b.order = order
 	b.groupby = groupby
 	b.limit = limit
 	b.offset = offset
 	b.parameters = parameters
+	b.named = named
+	b.err = err
 
 	return b
 }
@@ -244,10 +573,173 @@ func (b *builder) GetQuery() Query {
 	return &query{b}
 }
 
+func (b *builder) addOrder(orders []ordering, column, direction string) []ordering {
+	o, err := parseOrder(column, direction)
+	if err != nil {
+		b.setErr(err)
+		return orders
+	}
+
+	for i := range orders {
+		if orders[i].expr == o.expr {
+			orders[i] = o
+			return orders
+		}
+	}
+
+	return append(orders, o)
+}
+
+func (b *builder) join(kind string, table interface{}, alias string, on interface{}, parameters []interface{}) Builder {
+	j := join{kind: kind, alias: alias}
+
+	switch t := table.(type) {
+	case string:
+		j.table = t
+	default:
+		rt, ok := table.(reflect.Type)
+		if !ok {
+			rt = reflect.TypeOf(table)
+		}
+		if rt = indirectType(rt); rt != nil && rt.Kind() == reflect.Struct {
+			j.table = getTable(rt)
+		} else {
+			b.setErr(fmt.Errorf("builder: Unsupported join table type %T!", table))
+		}
+	}
+
+	if clauses := b.clause(nil, "", on, parameters); len(clauses) > 0 {
+		j.on = clauses[0].condition
+	}
+
+	if j.table != "" {
+		b.joins = append(b.joins, j)
+	}
+
+	return b
+}
+
+func (b *builder) entityKeys(values [][]interface{}, v reflect.Value, m *metadata) [][]interface{} {
+	if !v.IsValid() {
+		b.setErr(errors.New("builder: Nil entity!"))
+		return values
+	}
+	if v.Type() != b.t {
+		b.setErr(fmt.Errorf("builder: Unsupported entity type %s!", v.Type()))
+		return values
+	}
+
+	return append(values, m.keyValues(v))
+}
+
+func (b *builder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *builder) scope(where []clause, trashed trashedScope, qualifier string) []clause {
+	m := getMetadata(b.t)
+	if m.softDelete < 0 || trashed == withTrashed {
+		return where
+	}
+
+	col := m.field(m.softDelete).column
+	if qualifier != "" {
+		col = qualifier + "." + col
+	}
+	cond := raw(b.dialect.Quote(col) + " IS NULL")
+	if trashed == onlyTrashed {
+		cond = raw(b.dialect.Quote(col) + " IS NOT NULL")
+	}
+	if len(where) == 0 {
+		return []clause{{"", cond}}
+	}
+
+	return []clause{{"", group(where)}, {"AND", cond}}
+}
+
+func (b *builder) qualifier() string {
+	if len(b.joins) == 0 {
+		return ""
+	}
+	if b.alias != "" {
+		return b.alias
+	}
+	if fields := strings.Fields(b.from); len(fields) > 0 && isIdentifier(fields[len(fields)-1]) {
+		return fields[len(fields)-1]
+	}
+
+	return getTable(b.t)
+}
+
+func (b *builder) assign(column string, value Condition) {
+	if column == "" {
+		b.setErr(errors.New("builder: Empty update column!"))
+		return
+	}
+
+	for i, a := range b.sets {
+		if a.column == column {
+			b.sets[i].value = value
+			return
+		}
+	}
+	b.sets = append(b.sets, assignment{column, value})
+}
+
+func (b *builder) expr(sql string, parameters []interface{}) Condition {
+	if n := countParameters(sql, bracketQuotes(b.dialect)); n != len(parameters) {
+		b.setErr(fmt.Errorf("builder: Expected %d parameters for %q, got %d!", n, sql, len(parameters)))
+	}
+
+	return Expr(sql, parameters...)
+}
+
+func (b *builder) clause(clauses []clause, operator string, condition interface{}, parameters []interface{}) []clause {
+	if sql, ok := condition.(string); ok && len(parameters) > 0 {
+		condition = b.expr(sql, parameters)
+	} else if len(parameters) > 0 {
+		b.setErr(fmt.Errorf("builder: Unexpected parameters for %T condition!", condition))
+	}
+
+	c, ok := toCondition(condition)
+	if !ok {
+		b.setErr(fmt.Errorf("builder: Unsupported condition type %T!", condition))
+	}
+	if c == nil {
+		return clauses
+	}
+
+	return append(clauses, clause{operator, c})
+}
+
+func parseOrder(column, direction string) (ordering, error) {
+	o := ordering{expr: column}
+	if column == "" {
+		return o, errors.New("builder: Empty order expression!")
+	}
+
+	parts := strings.Fields(strings.ToUpper(direction))
+	if len(parts) > 0 && (parts[0] == "ASC" || parts[0] == "DESC") {
+		o.direction = parts[0]
+		parts = parts[1:]
+	}
+	if len(parts) == 2 && parts[0] == "NULLS" && (parts[1] == "FIRST" || parts[1] == "LAST") {
+		o.nulls = parts[1]
+		parts = parts[2:]
+	}
+	if len(parts) > 0 {
+		return o, fmt.Errorf("builder: Invalid order direction %q!", direction)
+	}
+
+	return o, nil
+}
+
 func newBuilder(t reflect.Type) Builder {
 	return &builder{
-		t:     t,
-		order: make(map[string]string),
+		t:       t,
+		dialect: DefaultDialect,
 	}
 }
 
@@ -3,6 +3,7 @@ package goquery
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -113,6 +114,55 @@ func TestFrom(t *testing.T) {
 	assert.Equal(t, "foo2", qBuilder.(*builder).from)
 }
 
+func TestAlias(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	qBuilder.Alias("u")
+	assert.Equal(t, "u", qBuilder.(*builder).alias)
+}
+
+func TestJoin(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+		post struct {
+			Id     int64 `json:"id" column:"id"`
+			UserId int64 `json:"user_id" column:"user_id"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	qBuilder.
+		Join("comments", "c", "c.user_id = u.id").
+		LeftJoin(reflect.TypeOf(post{}), "p", "p.user_id = u.id AND p.status = ?", "published").
+		RightJoin(&post{}, "p2", Eq("p2.id", 1)).
+		FullJoin(post{}, "p3", "p3.user_id = u.id").
+		CrossJoin("tags", "t")
+
+	assert.Equal(t, []join{
+		{"JOIN", "comments", "c", raw("c.user_id = u.id")},
+		{"LEFT JOIN", "posts", "p", Expr("p.user_id = u.id AND p.status = ?", "published")},
+		{"RIGHT JOIN", "posts", "p2", Eq("p2.id", 1)},
+		{"FULL JOIN", "posts", "p3", raw("p3.user_id = u.id")},
+		{"CROSS JOIN", "tags", "t", nil},
+	}, qBuilder.(*builder).joins)
+	assert.Nil(t, qBuilder.(*builder).err)
+
+	qBuilder.Join(1, "x", "x.id = u.id")
+	assert.Len(t, qBuilder.(*builder).joins, 5)
+	assert.EqualError(t, qBuilder.(*builder).err, "builder: Unsupported join table type int!")
+}
+
 func TestLimit(t *testing.T) {
 	type (
 		user struct {
@@ -154,22 +204,22 @@ func TestWhere(t *testing.T) {
 			Email string `json:"email" column:"email"`
 		}
 	)
-	var (
-		orWhere  []string
-		andWhere []string
-	)
 	reflectT := reflect.TypeOf(user{})
 	qBuilder := New(reflectT)
 
 	where := "col = ?"
 	qBuilder.Where(where)
-	assert.Equal(t, where, qBuilder.(*builder).where)
-	assert.Equal(t, andWhere, qBuilder.(*builder).andWhere)
-	assert.Equal(t, orWhere, qBuilder.(*builder).orWhere)
+	assert.Equal(t, []clause{{"", raw(where)}}, qBuilder.(*builder).where)
 
 	where = "col2 = ?"
-	qBuilder.Where(where)
-	assert.Equal(t, where, qBuilder.(*builder).where)
+	qBuilder.AndWhere("col3 = ?").Where(where)
+	assert.Equal(t, []clause{{"", raw(where)}}, qBuilder.(*builder).where)
+
+	qBuilder.Where(where, 1)
+	assert.Equal(t, []clause{{"", Expr(where, 1)}}, qBuilder.(*builder).where)
+
+	qBuilder.Where(Eq("col", 1), 2)
+	assert.EqualError(t, qBuilder.(*builder).err, "builder: Unexpected parameters for *goquery.comparison condition!")
 }
 
 func TestAndWhere(t *testing.T) {
@@ -184,11 +234,11 @@ func TestAndWhere(t *testing.T) {
 
 	andWhere := "col = ?"
 	qBuilder.AndWhere(andWhere)
-	assert.Equal(t, andWhere, qBuilder.(*builder).andWhere[0])
+	assert.Equal(t, clause{"AND", raw(andWhere)}, qBuilder.(*builder).where[0])
 
 	andWhere = "col2 = ?"
 	qBuilder.AndWhere(andWhere)
-	assert.Equal(t, andWhere, qBuilder.(*builder).andWhere[1])
+	assert.Equal(t, clause{"AND", raw(andWhere)}, qBuilder.(*builder).where[1])
 }
 
 func TestOrWhere(t *testing.T) {
@@ -203,11 +253,11 @@ func TestOrWhere(t *testing.T) {
 
 	orWhere := "col = ?"
 	qBuilder.OrWhere(orWhere)
-	assert.Equal(t, orWhere, qBuilder.(*builder).orWhere[0])
+	assert.Equal(t, clause{"OR", raw(orWhere)}, qBuilder.(*builder).where[0])
 
 	orWhere = "col2 = ?"
 	qBuilder.OrWhere(orWhere)
-	assert.Equal(t, orWhere, qBuilder.(*builder).orWhere[1])
+	assert.Equal(t, clause{"OR", raw(orWhere)}, qBuilder.(*builder).where[1])
 }
 
 func TestHaving(t *testing.T) {
@@ -217,22 +267,16 @@ func TestHaving(t *testing.T) {
 			Email string `json:"email" column:"email"`
 		}
 	)
-	var (
-		orHaving  []string
-		andHaving []string
-	)
 	reflectT := reflect.TypeOf(user{})
 	qBuilder := New(reflectT)
 
 	having := "COUNT(col) > ?"
 	qBuilder.Having(having)
-	assert.Equal(t, having, qBuilder.(*builder).having)
-	assert.Equal(t, andHaving, qBuilder.(*builder).andHaving)
-	assert.Equal(t, orHaving, qBuilder.(*builder).orHaving)
+	assert.Equal(t, []clause{{"", raw(having)}}, qBuilder.(*builder).having)
 
 	having = "COUNT(col2) > ?"
-	qBuilder.Having(having)
-	assert.Equal(t, having, qBuilder.(*builder).having)
+	qBuilder.OrHaving("COUNT(col3) > ?").Having(having)
+	assert.Equal(t, []clause{{"", raw(having)}}, qBuilder.(*builder).having)
 }
 
 func TestAndHaving(t *testing.T) {
@@ -247,11 +291,11 @@ func TestAndHaving(t *testing.T) {
 
 	having := "COUNT(col) > ?"
 	qBuilder.AndHaving(having)
-	assert.Equal(t, having, qBuilder.(*builder).andHaving[0])
+	assert.Equal(t, clause{"AND", raw(having)}, qBuilder.(*builder).having[0])
 
 	having = "COUNT(col2) > ?"
 	qBuilder.AndHaving(having)
-	assert.Equal(t, having, qBuilder.(*builder).andHaving[1])
+	assert.Equal(t, clause{"AND", raw(having)}, qBuilder.(*builder).having[1])
 }
 
 func TestOrHaving(t *testing.T) {
@@ -266,11 +310,11 @@ func TestOrHaving(t *testing.T) {
 
 	having := "COUNT(col) > ?"
 	qBuilder.OrHaving(having)
-	assert.Equal(t, having, qBuilder.(*builder).orHaving[0])
+	assert.Equal(t, clause{"OR", raw(having)}, qBuilder.(*builder).having[0])
 
 	having = "COUNT(col2) > ?"
 	qBuilder.OrHaving(having)
-	assert.Equal(t, having, qBuilder.(*builder).orHaving[1])
+	assert.Equal(t, clause{"OR", raw(having)}, qBuilder.(*builder).having[1])
 }
 
 func TestOrderBy(t *testing.T) {
@@ -283,13 +327,13 @@ func TestOrderBy(t *testing.T) {
 	reflectT := reflect.TypeOf(user{})
 	qBuilder := New(reflectT)
 
-	qBuilder.OrderBy("col", "ASC")
+	qBuilder.OrderBy("col", "ASC").OrderBy("col2", "desc nulls last")
 
 	qBuilder.(*builder).orderMu.Lock()
 	defer qBuilder.(*builder).orderMu.Unlock()
-	order := qBuilder.(*builder).order["col"]
+	order := qBuilder.(*builder).order
 
-	assert.Equal(t, "ASC", order)
+	assert.Equal(t, []ordering{{"col2", "DESC", "LAST"}}, order)
 }
 
 func TestAddOrderBy(t *testing.T) {
@@ -302,13 +346,30 @@ func TestAddOrderBy(t *testing.T) {
 	reflectT := reflect.TypeOf(user{})
 	qBuilder := New(reflectT)
 
-	qBuilder.AddOrderBy("col", "DESC")
+	qBuilder.AddOrderBy("col", "DESC").AddOrderBy("LOWER(col2)", "").AddOrderBy("col", "ASC NULLS FIRST")
 
 	qBuilder.(*builder).orderMu.Lock()
 	defer qBuilder.(*builder).orderMu.Unlock()
-	order := qBuilder.(*builder).order["col"]
+	order := qBuilder.(*builder).order
 
-	assert.Equal(t, "DESC", order)
+	assert.Equal(t, []ordering{{"col", "ASC", "FIRST"}, {"LOWER(col2)", "", ""}}, order)
+	assert.Nil(t, qBuilder.(*builder).err)
+}
+
+func TestAddOrderByInvalid(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	qBuilder.AddOrderBy("col", "DESC; DROP TABLE users")
+
+	assert.Empty(t, qBuilder.(*builder).order)
+	assert.EqualError(t, qBuilder.(*builder).err, `builder: Invalid order direction "DESC; DROP TABLE users"!`)
 }
 
 func TestGroupBy(t *testing.T) {
@@ -386,21 +447,27 @@ func TestReset(t *testing.T) {
 	var (
 		v          interface{}
 		statement  string
+		upsert     *upsert
+		force      bool
+		trashed    trashedScope
+		returning  []string
+		sets       []assignment
 		columns    []string
 		column     string
 		distinct   bool
 		from       string
-		where      string
-		orWhere    []string
-		andWhere   []string
-		having     string
-		orHaving   []string
-		andHaving  []string
-		order      map[string]string
+		alias      string
+		joins      []join
+		keys       Condition
+		where      []clause
+		having     []clause
+		order      []ordering
 		groupby    []string
 		limit      int64
 		offset     int64
 		parameters []interface{}
+		named      map[string]namedParameter
+		err        error
 	)
 
 	reflectT := reflect.TypeOf(user{})
@@ -410,8 +477,17 @@ func TestReset(t *testing.T) {
 		Select("col1", "col2", "col3").
 		Count("col4").
 		Save([]*user{&user{}, &user{}}).
+		OnConflict("email").
+		DoNothing().
+		Returning("id").
+		Set("email", "a@email.com").
+		ForceDelete().
+		OnlyTrashed().
 		Distinct(true).
 		From("foo").
+		Alias("f").
+		Join("bar", "b", "b.foo_id = f.id").
+		Find(1).
 		Where("col1 = ?").
 		AndWhere("col2 = ?").
 		OrWhere("col3 = ?").
@@ -425,25 +501,288 @@ func TestReset(t *testing.T) {
 		Limit(60).
 		Offset(2).
 		SetParameters(1, 2, 3).
-		AddParameters(6, 4, 5)
+		AddParameters(6, 4, 5).
+		SetNamedParameters(map[string]interface{}{"email": "a@email.com"})
 
 	qBuilder.Reset()
 
 	assert.Equal(t, v, qBuilder.(*builder).v)
 	assert.Equal(t, statement, qBuilder.(*builder).statement)
+	assert.Equal(t, upsert, qBuilder.(*builder).upsert)
+	assert.Equal(t, force, qBuilder.(*builder).force)
+	assert.Equal(t, trashed, qBuilder.(*builder).trashed)
+	assert.Equal(t, returning, qBuilder.(*builder).returning)
+	assert.Equal(t, sets, qBuilder.(*builder).sets)
 	assert.Equal(t, columns, qBuilder.(*builder).columns)
 	assert.Equal(t, column, qBuilder.(*builder).column)
 	assert.Equal(t, distinct, qBuilder.(*builder).distinct)
 	assert.Equal(t, from, qBuilder.(*builder).from)
+	assert.Equal(t, alias, qBuilder.(*builder).alias)
+	assert.Equal(t, joins, qBuilder.(*builder).joins)
+	assert.Equal(t, keys, qBuilder.(*builder).keys)
 	assert.Equal(t, where, qBuilder.(*builder).where)
-	assert.Equal(t, orWhere, qBuilder.(*builder).orWhere)
-	assert.Equal(t, andWhere, qBuilder.(*builder).andWhere)
 	assert.Equal(t, having, qBuilder.(*builder).having)
-	assert.Equal(t, orHaving, qBuilder.(*builder).orHaving)
-	assert.Equal(t, andHaving, qBuilder.(*builder).andHaving)
 	assert.Equal(t, order, qBuilder.(*builder).order)
 	assert.Equal(t, groupby, qBuilder.(*builder).groupby)
 	assert.Equal(t, limit, qBuilder.(*builder).limit)
 	assert.Equal(t, offset, qBuilder.(*builder).offset)
 	assert.Equal(t, parameters, qBuilder.(*builder).parameters)
+	assert.Equal(t, named, qBuilder.(*builder).named)
+	assert.Equal(t, err, qBuilder.(*builder).err)
+}
+
+func TestUpsert(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	qBuilder.OnConflict("email")
+	assert.Equal(t, &upsert{target: []string{"email"}}, qBuilder.(*builder).upsert)
+
+	qBuilder.DoNothing()
+	assert.Equal(t, &upsert{target: []string{"email"}, nothing: true}, qBuilder.(*builder).upsert)
+
+	qBuilder.DoUpdate("name")
+	assert.Equal(t, &upsert{target: []string{"email"}, update: []string{"name"}}, qBuilder.(*builder).upsert)
+}
+
+func TestReturning(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+
+	qBuilder := New(reflectT).SetDialect(PostgreSQL)
+	assert.Equal(t, `DELETE FROM "users" WHERE email = $1 RETURNING "id", "email"`, qBuilder.Delete().Where("email = ?", "a@email.com").Returning("id", "email").GetQuery().GetSQL())
+
+	qBuilder.Reset().Returning("email")
+	assert.Equal(t, `UPDATE "users" SET "email"=$1 WHERE "id"=$2 RETURNING "email"`, compileSQL(qBuilder.(*builder), "save"))
+
+	qBuilder = New(reflectT).SetDialect(SQLServer)
+	assert.Equal(t, `DELETE FROM [users] OUTPUT DELETED.[id] WHERE email = @p1`, qBuilder.Delete().Where("email = ?", "a@email.com").Returning("id").GetQuery().GetSQL())
+
+	qBuilder.Reset().Returning("id")
+	assert.Equal(t, `UPDATE [users] SET [email]=@p1 OUTPUT INSERTED.[id] WHERE [id]=@p2`, compileSQL(qBuilder.(*builder), "save"))
+	assert.Equal(t, `INSERT INTO [users] ([email]) OUTPUT INSERTED.[id] VALUES (@p1)`, compileSQL(qBuilder.(*builder), "insert"))
+
+	qBuilder.Save([]user{}).OnConflict("email")
+	assert.Equal(t, `MERGE INTO [users] t USING (VALUES (@p1)) AS s ([email]) ON (t.[email] = s.[email]) WHEN NOT MATCHED THEN INSERT ([email]) VALUES (s.[email]) OUTPUT INSERTED.[id];`, compileSQL(qBuilder.(*builder), "insert"))
+}
+
+func compileSQL(b *builder, statement string) string {
+	sql, _, _ := compile(b, statement).render(b.dialect, nil)
+
+	return sql
+}
+
+func TestUpdate(t *testing.T) {
+	type (
+		user struct {
+			Id        int64  `json:"id" column:"id"`
+			Status    string `json:"status" column:"status"`
+			LastLogin string `json:"last_login" column:"last_login"`
+			Logins    int64  `json:"logins" column:"logins"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	q := qBuilder.
+		Update().
+		Set("status", "inactive").
+		SetExpr("logins", "logins + ?", 1).
+		Where("last_login < ?", "2016-01-01").
+		GetQuery()
+	assert.Equal(t, "UPDATE users SET status = ?, logins = logins + ? WHERE last_login < ?", q.GetSQL())
+	assert.Equal(t, []interface{}{"inactive", 1, "2016-01-01"}, q.GetParameters())
+
+	q = qBuilder.
+		Reset().
+		SetDialect(MySQL).
+		Update().
+		SetMap(map[string]interface{}{"status": "active", "logins": 0}).
+		Set("status", "banned").
+		SetExpr("last_login", "NOW()").
+		Where("id = ?").
+		OrderBy("id", "ASC").
+		Limit(10).
+		SetParameters(1).
+		GetQuery()
+	assert.Equal(t, "UPDATE `users` SET `logins` = ?, `status` = ?, `last_login` = NOW() WHERE id = ? ORDER BY id ASC LIMIT 10", q.GetSQL())
+	assert.Equal(t, []interface{}{0, "banned", 1}, q.GetParameters())
+
+	qBuilder.Reset().SetDialect(Generic).Update().Set("", 1)
+	assert.EqualError(t, qBuilder.(*builder).err, "builder: Empty update column!")
+}
+
+func TestSoftDelete(t *testing.T) {
+	type (
+		user struct {
+			Id        int64      `json:"id" column:"id"`
+			Email     string     `json:"email" column:"email"`
+			DeletedAt *time.Time `json:"deleted_at" column:"deleted_at,softdelete"`
+		}
+		log struct {
+			Id      int64  `json:"id" column:"id"`
+			Message string `json:"message" column:"message"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	assert.Equal(t, "SELECT id, email, deleted_at FROM users WHERE (email = ? OR id = ?) AND deleted_at IS NULL", qBuilder.Select().Where("email = ?").OrWhere("id = ?").GetQuery().GetSQL())
+	assert.Equal(t, "SELECT COUNT(*) FROM users WHERE deleted_at IS NULL", qBuilder.Reset().Count("").GetQuery().GetSQL())
+	assert.Equal(t, "SELECT id, email, deleted_at FROM users WHERE id = ? AND deleted_at IS NULL", qBuilder.Reset().Find(1).GetQuery().GetSQL())
+	assert.Equal(t, "SELECT id, email, deleted_at FROM users", qBuilder.Reset().Select().WithTrashed().GetQuery().GetSQL())
+	assert.Equal(t, "SELECT id, email, deleted_at FROM users WHERE deleted_at IS NOT NULL", qBuilder.Reset().Select().OnlyTrashed().GetQuery().GetSQL())
+	assert.Equal(t, "SELECT u.id, u.email, u.deleted_at FROM users u JOIN groups g ON g.id = u.group_id WHERE u.deleted_at IS NULL", qBuilder.Reset().Select().Alias("u").Join("groups", "g", "g.id = u.group_id").GetQuery().GetSQL())
+	assert.Equal(t, "UPDATE users SET email = ? WHERE id = ? AND deleted_at IS NULL", qBuilder.Reset().Update().Set("email", "a@email.com").Where("id = ?", 1).GetQuery().GetSQL())
+
+	assert.Equal(t, "UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id IN (?, ?) AND deleted_at IS NULL", qBuilder.Reset().Delete(user{Id: 1}, user{Id: 2}).GetQuery().GetSQL())
+	assert.Equal(t, "DELETE FROM users WHERE id = ?", qBuilder.Reset().ForceDelete(user{Id: 1}).GetQuery().GetSQL())
+	assert.Equal(t, "DELETE FROM users WHERE deleted_at IS NOT NULL", qBuilder.Reset().OnlyTrashed().ForceDelete().GetQuery().GetSQL())
+	assert.Equal(t, "UPDATE users SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", qBuilder.Reset().Restore(user{Id: 1}).GetQuery().GetSQL())
+
+	qBuilder = New(reflect.TypeOf(log{}))
+	assert.Equal(t, "DELETE FROM logs WHERE id = ?", qBuilder.Delete(log{Id: 1}).GetQuery().GetSQL())
+
+	qBuilder.Reset().Restore()
+	assert.EqualError(t, qBuilder.(*builder).err, "builder: No soft delete column for log!")
+}
+
+func TestFind(t *testing.T) {
+	type (
+		membership struct {
+			UserId  int64  `column:"user_id,pk"`
+			GroupId int64  `column:"group_id,pk"`
+			Role    string `column:"role"`
+		}
+	)
+	reflectT := reflect.TypeOf(membership{})
+	qBuilder := New(reflectT)
+
+	q := qBuilder.Select("role").Find(1, 2).Where("role <> ?", "guest").GetQuery()
+	assert.Equal(t, "SELECT user_id, group_id, role FROM memberships WHERE (user_id = ? AND group_id = ?) AND role <> ?", q.GetSQL())
+	assert.Equal(t, []interface{}{1, 2, "guest"}, q.GetParameters())
+
+	qBuilder.Reset().Find(1)
+	assert.EqualError(t, qBuilder.(*builder).err, "builder: Expected 2 primary key values for membership, got 1!")
+}
+
+func TestDeleteEntities(t *testing.T) {
+	type (
+		user struct {
+			Email string `json:"email" column:"email"`
+			Uuid  string `json:"uuid" column:"uuid,pk"`
+		}
+		log struct {
+			Message string `column:"message"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	q := qBuilder.Delete(user{Uuid: "a"}, &user{Uuid: "b"}, []*user{{Uuid: "c"}}).GetQuery()
+	assert.Equal(t, "DELETE FROM users WHERE uuid IN (?, ?, ?)", q.GetSQL())
+	assert.Equal(t, []interface{}{"a", "b", "c"}, q.GetParameters())
+
+	q = qBuilder.Reset().Delete([]user{}).GetQuery()
+	assert.Equal(t, "DELETE FROM users WHERE 1=0", q.GetSQL())
+
+	q = qBuilder.Reset().Delete().Where("email = ?", "a@email.com").GetQuery()
+	assert.Equal(t, "DELETE FROM users WHERE email = ?", q.GetSQL())
+
+	qBuilder.Reset().Delete(log{})
+	assert.EqualError(t, qBuilder.(*builder).err, "builder: Unsupported entity type goquery.log!")
+
+	qBuilder = New(reflect.TypeOf(log{})).Delete(log{})
+	assert.EqualError(t, qBuilder.(*builder).err, "builder: No primary key for log!")
+}
+
+func TestSetNamedParameters(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	qBuilder.SetNamedParameters(map[string]interface{}{"id": 1})
+	assert.Equal(t, map[string]namedParameter{"id": {1, false}}, qBuilder.(*builder).named)
+
+	qBuilder.SetNamedParameters(&user{2, "a@email.com"}).AddNamedParameters(map[string]string{"status": "active"})
+	assert.Equal(t, map[string]namedParameter{"id": {int64(2), true}, "email": {"a@email.com", true}, "status": {"active", false}}, qBuilder.(*builder).named)
+	assert.NoError(t, qBuilder.(*builder).err)
+
+	qBuilder.SetNamedParameters([]int{1})
+	assert.EqualError(t, qBuilder.(*builder).err, "builder: Unsupported named parameters type []int!")
+}
+
+func TestSetDialect(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	assert.Equal(t, DefaultDialect, qBuilder.(*builder).dialect)
+
+	qBuilder.SetDialect(PostgreSQL)
+	assert.Equal(t, PostgreSQL, qBuilder.(*builder).dialect)
+
+	qBuilder.Reset()
+	assert.Equal(t, PostgreSQL, qBuilder.(*builder).dialect)
+
+	qBuilder.SetDialect(nil)
+	assert.Equal(t, DefaultDialect, qBuilder.(*builder).dialect)
+}
+
+func TestOnColumns(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	assert.Equal(t, ScanIgnore, qBuilder.(*builder).unknownColumns)
+	assert.Equal(t, ScanIgnore, qBuilder.(*builder).missingColumns)
+
+	qBuilder.OnUnknownColumns(ScanCollect).OnMissingColumns(ScanError)
+	assert.Equal(t, ScanCollect, qBuilder.(*builder).unknownColumns)
+	assert.Equal(t, ScanError, qBuilder.(*builder).missingColumns)
+}
+
+func TestOnEmptySlice(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	assert.Equal(t, EmptySliceFalse, qBuilder.(*builder).emptySlices)
+
+	qBuilder.OnEmptySlice(EmptySliceError)
+	assert.Equal(t, EmptySliceError, qBuilder.(*builder).emptySlices)
+
+	qBuilder.Reset()
+	assert.Equal(t, EmptySliceError, qBuilder.(*builder).emptySlices)
 }
@@ -0,0 +1,610 @@
+package goquery
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	sqlQuery struct {
+		head    string
+		from    string
+		joins   []join
+		where   []clause
+		groupBy []string
+		having  []clause
+		orderBy []string
+		limit   int64
+		offset  int64
+		sets    []assignment
+
+		output    string
+		values    string
+		returning string
+
+		named       map[string]namedParameter
+		emptySlices EmptySlicePolicy
+	}
+
+	group []clause
+
+	arguments struct {
+		values   []interface{}
+		bound    []bool
+		brackets bool
+		named    map[string]namedParameter
+		used     map[string]bool
+		err      error
+	}
+)
+
+const emptySlice = "\x00"
+
+func compile(b *builder, statement string) *sqlQuery {
+	var (
+		d     = b.dialect
+		table = d.Quote(getTable(b.t))
+		s     = &sqlQuery{named: b.named, emptySlices: b.emptySlices}
+	)
+
+	switch statement {
+	case "select", "count":
+		s.head = "SELECT "
+		if statement == "count" {
+			s.head += "COUNT("
+		}
+		if b.distinct {
+			s.head += "DISTINCT "
+		}
+		if statement == "count" {
+			s.head += b.column + ")"
+		} else {
+			cols, _ := selectColumns(b.t, b.columns, b.qualifier())
+			for i, col := range cols {
+				if isIdentifier(col) {
+					cols[i] = d.Quote(col)
+				}
+			}
+			s.head += strings.Join(cols, ", ")
+		}
+
+		s.from = table
+		if b.from != "" {
+			s.from = b.from
+		}
+		if b.alias != "" {
+			s.from += " " + b.alias
+		}
+		for _, j := range b.joins {
+			if isIdentifier(j.table) {
+				j.table = d.Quote(j.table)
+			}
+			s.joins = append(s.joins, j)
+		}
+		s.where = b.scope(keyed(b.keys, b.where), b.trashed, b.qualifier())
+		s.groupBy = b.groupby
+		s.having = b.having
+		if statement == "select" {
+			s.orderBy = compileOrder(b)
+			s.limit = b.limit
+			s.offset = b.offset
+		}
+	case "insert":
+		compileInsert(b, s, getMetadata(b.t).insertFields(reflect.Value{}, false), 1, b.returning)
+	case "update":
+		s.head = "UPDATE " + table
+		for _, a := range b.sets {
+			if isIdentifier(a.column) {
+				a.column = d.Quote(a.column)
+			}
+			s.sets = append(s.sets, a)
+		}
+		s.output, s.returning = d.Returning(b.returning, false)
+		s.where = b.scope(keyed(b.keys, b.where), b.trashed, "")
+		s.orderBy = compileOrder(b)
+		s.limit = b.limit
+		s.offset = b.offset
+	case "save":
+		compileSave(b, s, getMetadata(b.t).updateFields())
+	case "delete", "restore":
+		var (
+			m       = getMetadata(b.t)
+			trashed = b.trashed
+		)
+		switch {
+		case statement == "restore":
+			s.head = "UPDATE " + table
+			s.sets = []assignment{{d.Quote(m.field(m.softDelete).column), raw("NULL")}}
+			s.output, s.returning = d.Returning(b.returning, false)
+			trashed = onlyTrashed
+		case m.softDelete >= 0 && !b.force:
+			s.head = "UPDATE " + table
+			s.sets = []assignment{{d.Quote(m.field(m.softDelete).column), raw("CURRENT_TIMESTAMP")}}
+			s.output, s.returning = d.Returning(b.returning, false)
+		default:
+			s.head = "DELETE"
+			s.from = table
+			s.output, s.returning = d.Returning(b.returning, true)
+			if trashed == withoutTrashed {
+				trashed = withTrashed
+			}
+		}
+		s.where = b.scope(keyed(b.keys, b.where), trashed, "")
+		s.orderBy = compileOrder(b)
+		s.limit = b.limit
+		s.offset = b.offset
+	}
+
+	return s
+}
+
+func compileSave(b *builder, s *sqlQuery, fields []writeField) *sqlQuery {
+	var (
+		d          = b.dialect
+		m          = getMetadata(b.t)
+		sets, keys []string
+	)
+	for _, f := range fields {
+		sets = append(sets, d.Quote(f.column)+"=?")
+	}
+	for _, col := range m.keys {
+		keys = append(keys, d.Quote(col)+"=?")
+	}
+	if m.version >= 0 {
+		col := d.Quote(m.field(m.version).column)
+		sets = append(sets, col+"=?")
+		keys = append(keys, col+"=?")
+	}
+	s.head = "UPDATE " + d.Quote(getTable(b.t)) + " SET " + strings.Join(sets, ", ")
+	s.output, s.returning = d.Returning(b.returning, false)
+	s.where = keyed(raw(strings.Join(keys, " AND ")), b.where)
+
+	return s
+}
+
+func compileInsert(b *builder, s *sqlQuery, fields []writeField, rows int, returning []string) *sqlQuery {
+	var cols, row []string
+	for _, f := range fields {
+		cols = append(cols, f.column)
+		if f.expr != "" {
+			row = append(row, f.expr)
+		} else {
+			row = append(row, "?")
+		}
+	}
+
+	values := make([][]string, rows)
+	for i := range values {
+		values[i] = row
+	}
+
+	table := getTable(b.t)
+	s.output, s.returning = b.dialect.Returning(returning, false)
+	if b.upsert == nil {
+		s.head = insertInto(b.dialect, table, cols)
+		s.values = " VALUES " + valuesList(values)
+
+		return s
+	}
+
+	target := b.upsert.target
+	if len(target) == 0 {
+		target = getMetadata(b.t).keys
+	}
+
+	var update []string
+	if !b.upsert.nothing {
+		update = b.upsert.update
+		if len(update) == 0 {
+			for _, col := range cols {
+				if !containsColumn(target, col) && !containsColumn(getMetadata(b.t).keys, col) {
+					update = append(update, col)
+				}
+			}
+		}
+	}
+
+	s.head = b.dialect.Upsert(table, cols, values, target, update)
+	if s.output != "" {
+		s.head = strings.TrimSuffix(s.head, ";") + s.output + ";"
+		s.output = ""
+	}
+
+	return s
+}
+
+func containsColumn(columns []string, column string) bool {
+	for _, col := range columns {
+		if strings.EqualFold(col, column) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func keyed(keys Condition, where []clause) []clause {
+	if keys == nil || keys == raw("") {
+		return where
+	}
+
+	keyedWhere := []clause{{"", keys}}
+	if len(where) > 0 {
+		keyedWhere = append(keyedWhere, clause{"AND", group(where)})
+	}
+
+	return keyedWhere
+}
+
+func compileOrder(b *builder) []string {
+	b.orderMu.RLock()
+	defer b.orderMu.RUnlock()
+
+	var orderBy []string
+	for _, o := range b.order {
+		sql := o.expr
+		if o.direction != "" {
+			sql += " " + o.direction
+		}
+
+		switch {
+		case o.nulls == "":
+		case b.dialect.NullsOrder():
+			sql += " NULLS " + o.nulls
+		case o.nulls == "FIRST":
+			orderBy = append(orderBy, "CASE WHEN "+o.expr+" IS NULL THEN 0 ELSE 1 END")
+		default:
+			orderBy = append(orderBy, "CASE WHEN "+o.expr+" IS NULL THEN 1 ELSE 0 END")
+		}
+		orderBy = append(orderBy, sql)
+	}
+
+	return orderBy
+}
+
+func (s *sqlQuery) render(d Dialect, parameters []interface{}) (string, []interface{}, error) {
+	var (
+		args = arguments{brackets: bracketQuotes(d), named: s.named, used: make(map[string]bool)}
+		sql  string
+	)
+
+	sql += args.raw(s.head)
+	for i, a := range s.sets {
+		if i == 0 {
+			sql += " SET "
+		} else {
+			sql += ", "
+		}
+		sql += a.column + " = " + args.condition(a.value)
+	}
+	if s.from != "" {
+		sql += " FROM " + args.raw(s.from)
+	}
+	for _, j := range s.joins {
+		sql += " " + j.kind + " " + args.raw(j.table)
+		if j.alias != "" {
+			sql += " " + j.alias
+		}
+		if j.on != nil {
+			sql += " ON " + args.condition(j.on)
+		}
+	}
+	sql += s.output + args.raw(s.values)
+	if where := args.clauses(s.where); where != "" {
+		sql += " WHERE " + where
+	}
+	if len(s.groupBy) > 0 {
+		sql += " GROUP BY " + strings.Join(s.groupBy, ", ")
+	}
+	if having := args.clauses(s.having); having != "" {
+		sql += " HAVING " + having
+	}
+	if len(s.orderBy) > 0 {
+		sql += " ORDER BY " + strings.Join(s.orderBy, ", ")
+	}
+	sql += d.LimitOffset(s.limit, s.offset, len(s.orderBy) > 0)
+	sql += s.returning
+
+	if err := args.verify(); err != nil {
+		return rebind(d, sql), nil, err
+	}
+
+	sql, values, err := expandSlices(d, sql, args.bind(parameters), s.emptySlices)
+
+	return rebind(d, sql), values, err
+}
+
+func expandSlices(d Dialect, sql string, args []interface{}, policy EmptySlicePolicy) (string, []interface{}, error) {
+	var (
+		expanded []interface{}
+		used     int
+		err      error
+	)
+	sql = replacePlaceholders(sql, bracketQuotes(d), func(n int) string {
+		if n > len(args) {
+			return "?"
+		}
+		used = n
+
+		values, ok := sliceValues(args[n-1])
+		if !ok {
+			expanded = append(expanded, args[n-1])
+			return "?"
+		}
+		if len(values) == 0 {
+			if policy == EmptySliceError && err == nil {
+				err = fmt.Errorf("query: Empty slice for parameter %d!", n)
+			}
+			return emptySlice
+		}
+
+		expanded = append(expanded, values...)
+		return "?" + strings.Repeat(", ?", len(values)-1)
+	})
+
+	return emptyPredicates(sql), append(expanded, args[used:]...), err
+}
+
+func emptyPredicates(sql string) string {
+	for {
+		i := strings.Index(sql, emptySlice)
+		if i < 0 {
+			return sql
+		}
+
+		open := strings.TrimRight(sql[:i], " ")
+		closing := strings.TrimLeft(sql[i+len(emptySlice):], " ")
+		if !strings.HasSuffix(open, "(") || !strings.HasPrefix(closing, ")") {
+			sql = sql[:i] + "NULL" + sql[i+len(emptySlice):]
+			continue
+		}
+
+		start, not := inOperand(strings.TrimRight(open[:len(open)-1], " "))
+		if start < 0 {
+			sql = sql[:i] + "NULL" + sql[i+len(emptySlice):]
+			continue
+		}
+
+		predicate := "1=0"
+		if not {
+			predicate = "1=1"
+		}
+		sql = sql[:start] + predicate + closing[1:]
+	}
+}
+
+func inOperand(sql string) (int, bool) {
+	upper := strings.ToUpper(sql)
+	if !strings.HasSuffix(upper, " IN") {
+		return -1, false
+	}
+
+	sql = strings.TrimRight(sql[:len(sql)-3], " ")
+	not := strings.HasSuffix(strings.ToUpper(sql), " NOT")
+	if not {
+		sql = strings.TrimRight(sql[:len(sql)-4], " ")
+	}
+
+	i := len(sql)
+	if strings.HasSuffix(sql, ")") {
+		depth := 0
+		for i--; i >= 0; i-- {
+			if sql[i] == ')' {
+				depth++
+			} else if sql[i] == '(' {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		if i < 0 {
+			return -1, false
+		}
+	}
+	for i > 0 && isOperandPart(sql[i-1]) {
+		i--
+	}
+	if i == len(sql) {
+		return -1, false
+	}
+
+	return i, not
+}
+
+func isOperandPart(c byte) bool {
+	return isNamePart(c) || c == '.' || c == '"' || c == '`' || c == '[' || c == ']'
+}
+
+func (g group) ToSQL() (string, []interface{}) {
+	var args arguments
+	sql := args.clauses(g)
+
+	return sql, args.values
+}
+
+func (a *arguments) raw(sql string) string {
+	return a.expr(sql, nil)
+}
+
+func (a *arguments) expr(sql string, values []interface{}) string {
+	sql = replaceParameters(sql, a.brackets, func(token, name string) string {
+		if name == "" {
+			if len(values) > 0 {
+				a.values = append(a.values, values[0])
+				a.bound = append(a.bound, true)
+				values = values[1:]
+			} else {
+				a.values = append(a.values, nil)
+				a.bound = append(a.bound, false)
+			}
+			return token
+		}
+		if a.named == nil {
+			return token
+		}
+
+		p, ok := a.named[name]
+		if !ok {
+			if a.err == nil {
+				a.err = fmt.Errorf("query: Missing named parameter %q!", name)
+			}
+			return token
+		}
+		a.used[name] = true
+		a.values = append(a.values, p.value)
+		a.bound = append(a.bound, true)
+
+		return "?"
+	})
+	for _, value := range values {
+		a.values = append(a.values, value)
+		a.bound = append(a.bound, true)
+	}
+
+	return sql
+}
+
+func (a *arguments) condition(c Condition) string {
+	switch c := c.(type) {
+	case raw:
+		return a.raw(string(c))
+	case *expr:
+		return a.expr(c.sql, c.args)
+	case group:
+		return a.clauses(c)
+	case *junction:
+		return c.render(a.condition)
+	case *not:
+		return c.render(a.condition)
+	}
+
+	sql, values := c.ToSQL()
+	for _, value := range values {
+		a.values = append(a.values, value)
+		a.bound = append(a.bound, true)
+	}
+
+	return sql
+}
+
+func (a *arguments) clauses(clauses []clause) string {
+	var (
+		sql      string
+		operator string
+		terms    int
+	)
+	for _, c := range clauses {
+		part := a.condition(c.condition)
+		if part == "" {
+			continue
+		}
+		if len(clauses) > 1 && needsParens(part) {
+			part = "(" + part + ")"
+		}
+
+		switch {
+		case terms == 0:
+			sql = part
+		case terms > 1 && c.operator != operator:
+			sql = "(" + sql + ") " + c.operator + " " + part
+		default:
+			sql += " " + c.operator + " " + part
+		}
+		if terms > 0 {
+			operator = c.operator
+		}
+		terms++
+	}
+
+	return sql
+}
+
+func (a *arguments) bind(parameters []interface{}) []interface{} {
+	var args []interface{}
+	for i, value := range a.values {
+		if !a.bound[i] {
+			if len(parameters) == 0 {
+				continue
+			}
+			value, parameters = parameters[0], parameters[1:]
+		}
+		args = append(args, value)
+	}
+
+	return append(args, parameters...)
+}
+
+func (a *arguments) verify() error {
+	if a.err != nil {
+		return a.err
+	}
+
+	var names []string
+	for name, p := range a.named {
+		if !p.optional && !a.used[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return fmt.Errorf("query: Unused named parameter %q!", names[0])
+	}
+
+	return nil
+}
+
+func countParameters(sql string, brackets bool) int {
+	var n int
+	replaceParameters(sql, brackets, func(token, name string) string {
+		if name == "" {
+			n++
+		}
+		return token
+	})
+
+	return n
+}
+
+func replaceParameters(sql string, brackets bool, parameter func(token, name string) string) string {
+	var (
+		buf   strings.Builder
+		quote byte
+	)
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '[' && brackets:
+			quote = ']'
+		case c == '?':
+			buf.WriteString(parameter("?", ""))
+			continue
+		case (c == ':' || c == '@') && i+1 < len(sql) && isNameStart(sql[i+1]) && (i == 0 || !isNamePart(sql[i-1]) && sql[i-1] != c):
+			j := i + 1
+			for j < len(sql) && isNamePart(sql[j]) {
+				j++
+			}
+			buf.WriteString(parameter(sql[i:j], sql[i+1:j]))
+			i = j - 1
+			continue
+		}
+		buf.WriteByte(c)
+	}
+
+	return buf.String()
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9'
+}
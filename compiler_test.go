@@ -0,0 +1,181 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderClauseOrder(t *testing.T) {
+	s := &sqlQuery{
+		offset:  20,
+		limit:   10,
+		orderBy: []string{"total DESC"},
+		having:  []clause{{"", Gt("SUM(total)", 100)}},
+		groupBy: []string{"user_id"},
+		where:   []clause{{"", raw("status = ?")}, {"AND", Lt("created_at", "2016-01-01")}},
+		joins:   []join{{"JOIN", "users", "u", raw("u.id = o.user_id")}},
+		from:    "orders o",
+		head:    "SELECT user_id, SUM(total)",
+	}
+
+	sql, args, err := s.render(PostgreSQL, []interface{}{"paid"})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT user_id, SUM(total) FROM orders o JOIN users u ON u.id = o.user_id "+
+		"WHERE status = $1 AND created_at < $2 GROUP BY user_id HAVING SUM(total) > $3 "+
+		"ORDER BY total DESC LIMIT 10 OFFSET 20", sql)
+	assert.Equal(t, []interface{}{"paid", "2016-01-01", 100}, args)
+}
+
+func TestCompileUpdateWhere(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	qBuilder := New(reflect.TypeOf(user{})).Where("email = ?").OrWhere(IsNull("email"))
+	sql, _, _ := compile(qBuilder.(*builder), "save").render(Generic, nil)
+	assert.Equal(t, "UPDATE users SET email=? WHERE id=? AND (email = ? OR email IS NULL)", sql)
+
+	sql, _, _ = compile(qBuilder.(*builder), "insert").render(Generic, nil)
+	assert.Equal(t, "INSERT INTO users (email) VALUES (?)", sql)
+}
+
+func TestExpandSlices(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	qBuilder := New(reflect.TypeOf(user{}))
+
+	for _, tc := range []struct {
+		dialect Dialect
+		sql     string
+	}{
+		{Generic, "DELETE FROM users WHERE id IN (?, ?) AND email <> ?"},
+		{MySQL, "DELETE FROM `users` WHERE id IN (?, ?) AND email <> ?"},
+		{PostgreSQL, "DELETE FROM \"users\" WHERE id IN ($1, $2) AND email <> $3"},
+		{SQLServer, "DELETE FROM [users] WHERE id IN (@p1, @p2) AND email <> @p3"},
+		{Oracle, "DELETE FROM \"users\" WHERE id IN (:1, :2) AND email <> :3"},
+	} {
+		q := qBuilder.Reset().SetDialect(tc.dialect).Delete().Where("id IN (?)").AndWhere(NotEq("email", "a@email.com")).SetParameters([]int{1, 2}).GetQuery()
+		assert.Equal(t, tc.sql, q.GetSQL())
+		assert.Equal(t, []interface{}{1, 2, "a@email.com"}, q.GetParameters())
+	}
+
+	q := qBuilder.Reset().SetDialect(Generic).Select("id").Where("email = ?").SetParameters([]byte("a@email.com")).GetQuery()
+	assert.Equal(t, "SELECT id FROM users WHERE email = ?", q.GetSQL())
+	assert.Equal(t, []interface{}{[]byte("a@email.com")}, q.GetParameters())
+}
+
+func TestExpandEmptySlices(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	qBuilder := New(reflect.TypeOf(user{}))
+
+	q := qBuilder.Select("id").Where("id IN (?)").OrWhere("email NOT IN (?)").SetParameters([]int{}, []string{}).GetQuery()
+	assert.Equal(t, "SELECT id FROM users WHERE 1=0 OR 1=1", q.GetSQL())
+	assert.Empty(t, q.GetParameters())
+
+	q = qBuilder.Reset().SetDialect(PostgreSQL).Select("id").Where("(users.id IN (?) AND LOWER(email) not in(?)) OR id = ?").SetParameters([]int{}, []string{}, 1).GetQuery()
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE (1=0 AND 1=1) OR id = $1`, q.GetSQL())
+	assert.Equal(t, []interface{}{1}, q.GetParameters())
+
+	q = qBuilder.Reset().SetDialect(PostgreSQL).Select("id").Where("id = ANY(?)").SetParameters([]int{}).GetQuery()
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE id = ANY(NULL)`, q.GetSQL())
+
+	_, err := qBuilder.Reset().OnEmptySlice(EmptySliceError).Delete().Where("id IN (?)").SetParameters([]int{}).GetQuery().Execute(nil)
+	assert.EqualError(t, err, "query: Empty slice for parameter 1!")
+}
+
+func TestNamedParameters(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	qBuilder := New(reflect.TypeOf(user{}))
+
+	q := qBuilder.
+		SetDialect(PostgreSQL).
+		Select("id").
+		Where("email = :email AND status = ?").
+		AndWhere("created_at::date > @since").
+		OrWhere(Eq("id", 7)).
+		GroupBy("id").
+		Having("COUNT(id) > :count").
+		SetParameters("active").
+		SetNamedParameters(map[string]interface{}{"email": "a@email.com", "since": "2016-01-01", "count": 2}).
+		GetQuery()
+
+	assert.Equal(t, "SELECT \"id\" FROM \"users\" WHERE ((email = $1 AND status = $2) AND created_at::date > $3) OR id = $4 GROUP BY id HAVING COUNT(id) > $5", q.GetSQL())
+	assert.Equal(t, []interface{}{"a@email.com", "active", "2016-01-01", 7, 2}, q.GetParameters())
+
+	q = qBuilder.Reset().SetDialect(Generic).Select("id").Where("email = :email OR email = ':email'").SetNamedParameters(user{Email: "a@email.com"}).GetQuery()
+	assert.Equal(t, "SELECT id FROM users WHERE email = ? OR email = ':email'", q.GetSQL())
+	assert.Equal(t, []interface{}{"a@email.com"}, q.GetParameters())
+
+	q = qBuilder.Reset().Select("id").Where("id IN (:ids)").SetNamedParameters(map[string]interface{}{"ids": []int{1, 2}}).GetQuery()
+	assert.Equal(t, "SELECT id FROM users WHERE id IN (?, ?)", q.GetSQL())
+	assert.Equal(t, []interface{}{1, 2}, q.GetParameters())
+
+	_, err := qBuilder.Reset().Delete().Where("email = :email").SetNamedParameters(map[string]interface{}{"mail": "a@email.com"}).GetQuery().Execute(nil)
+	assert.EqualError(t, err, "query: Missing named parameter \"email\"!")
+
+	_, err = qBuilder.Reset().Delete().Where("email = :email").SetNamedParameters(map[string]interface{}{"email": "a@email.com", "id": 1}).GetQuery().Execute(nil)
+	assert.EqualError(t, err, "query: Unused named parameter \"id\"!")
+
+	q = qBuilder.Reset().SetDialect(MySQL).Select("id").Where("id > @last_id AND created_at > ?", "2016-01-01").GetQuery()
+	assert.Equal(t, "SELECT `id` FROM `users` WHERE id > @last_id AND created_at > ?", q.GetSQL())
+	assert.Equal(t, []interface{}{"2016-01-01"}, q.GetParameters())
+}
+
+func TestClauseParameters(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	qBuilder := New(reflect.TypeOf(user{}))
+
+	q := qBuilder.
+		SetDialect(PostgreSQL).
+		Select("id").
+		Having("COUNT(id) > ?", 2).
+		AndWhere("status = ?", "old").
+		Where("email = ?", "a@email.com").
+		OrWhere("id IN (?)", []int{1, 2}).
+		LeftJoin("orders", "o", "o.user_id = users.id AND o.total > ?", 50).
+		GroupBy("id").
+		GetQuery()
+
+	assert.Equal(t, "SELECT \"users\".\"id\" FROM \"users\" LEFT JOIN \"orders\" o ON o.user_id = users.id AND o.total > $1 "+
+		"WHERE email = $2 OR id IN ($3, $4) GROUP BY id HAVING COUNT(id) > $5", q.GetSQL())
+	assert.Equal(t, []interface{}{50, "a@email.com", 1, 2, 2}, q.GetParameters())
+
+	qBuilder.Reset().SetDialect(Generic).Select("id").Where("id = ? AND email = ?", 1).AndWhere("status = ?").SetParameters(9)
+	assert.EqualError(t, qBuilder.(*builder).err, `builder: Expected 2 parameters for "id = ? AND email = ?", got 1!`)
+
+	q = qBuilder.
+		Reset().
+		Select("id").
+		Where("email = :email AND id = ?", 1).
+		AndWhere(Or(Expr("status = :status"), Not(Expr("id IN (?)", []int{2, 3})))).
+		SetNamedParameters(map[string]interface{}{"email": "a@email.com", "status": "active"}).
+		GetQuery()
+	assert.Equal(t, "SELECT id FROM users WHERE (email = ? AND id = ?) AND (status = ? OR NOT (id IN (?, ?)))", q.GetSQL())
+	assert.Equal(t, []interface{}{"a@email.com", 1, "active", 2, 3}, q.GetParameters())
+
+	_, err := qBuilder.Reset().Delete().Where("email = :email AND id = ?", 1).SetNamedParameters(map[string]interface{}{}).GetQuery().Execute(nil)
+	assert.EqualError(t, err, `query: Missing named parameter "email"!`)
+}
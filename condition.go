@@ -0,0 +1,290 @@
+package goquery
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+)
+
+type (
+	Condition interface {
+		ToSQL() (string, []interface{})
+	}
+
+	raw string
+
+	expr struct {
+		sql  string
+		args []interface{}
+	}
+
+	comparison struct {
+		column   string
+		operator string
+		value    interface{}
+	}
+
+	in struct {
+		column string
+		values []interface{}
+		not    bool
+	}
+
+	between struct {
+		column   string
+		from, to interface{}
+	}
+
+	null struct {
+		column string
+		not    bool
+	}
+
+	junction struct {
+		operator   string
+		conditions []Condition
+	}
+
+	not struct {
+		condition Condition
+	}
+)
+
+func Expr(sql string, args ...interface{}) Condition {
+	return &expr{sql, args}
+}
+
+func Eq(column string, value interface{}) Condition {
+	return &comparison{column, "=", value}
+}
+
+func NotEq(column string, value interface{}) Condition {
+	return &comparison{column, "<>", value}
+}
+
+func Gt(column string, value interface{}) Condition {
+	return &comparison{column, ">", value}
+}
+
+func Gte(column string, value interface{}) Condition {
+	return &comparison{column, ">=", value}
+}
+
+func Lt(column string, value interface{}) Condition {
+	return &comparison{column, "<", value}
+}
+
+func Lte(column string, value interface{}) Condition {
+	return &comparison{column, "<=", value}
+}
+
+func Like(column string, pattern interface{}) Condition {
+	return &comparison{column, "LIKE", pattern}
+}
+
+func In(column string, values ...interface{}) Condition {
+	return &in{column, flatten(values), false}
+}
+
+func NotIn(column string, values ...interface{}) Condition {
+	return &in{column, flatten(values), true}
+}
+
+func Between(column string, from, to interface{}) Condition {
+	return &between{column, from, to}
+}
+
+func IsNull(column string) Condition {
+	return &null{column, false}
+}
+
+func IsNotNull(column string) Condition {
+	return &null{column, true}
+}
+
+func And(conditions ...Condition) Condition {
+	return &junction{"AND", conditions}
+}
+
+func Or(conditions ...Condition) Condition {
+	return &junction{"OR", conditions}
+}
+
+func Not(condition Condition) Condition {
+	return &not{condition}
+}
+
+func (r raw) ToSQL() (string, []interface{}) {
+	return string(r), nil
+}
+
+func (e *expr) ToSQL() (string, []interface{}) {
+	return e.sql, e.args
+}
+
+func (c *comparison) ToSQL() (string, []interface{}) {
+	return c.column + " " + c.operator + " ?", []interface{}{c.value}
+}
+
+func (c *in) ToSQL() (string, []interface{}) {
+	if len(c.values) == 0 {
+		if c.not {
+			return "1=1", nil
+		}
+		return "1=0", nil
+	}
+
+	sql := c.column
+	if c.not {
+		sql += " NOT"
+	}
+	sql += " IN (?" + strings.Repeat(", ?", len(c.values)-1) + ")"
+
+	return sql, c.values
+}
+
+func (c *between) ToSQL() (string, []interface{}) {
+	return c.column + " BETWEEN ? AND ?", []interface{}{c.from, c.to}
+}
+
+func (c *null) ToSQL() (string, []interface{}) {
+	if c.not {
+		return c.column + " IS NOT NULL", nil
+	}
+
+	return c.column + " IS NULL", nil
+}
+
+func (c *junction) ToSQL() (string, []interface{}) {
+	var args []interface{}
+	sql := c.render(func(condition Condition) string {
+		sql, conditionArgs := condition.ToSQL()
+		if sql != "" {
+			args = append(args, conditionArgs...)
+		}
+		return sql
+	})
+
+	return sql, args
+}
+
+func (c *junction) render(part func(Condition) string) string {
+	var parts []string
+	for _, condition := range c.conditions {
+		if condition == nil {
+			continue
+		}
+		if sql := part(condition); sql != "" {
+			parts = append(parts, sql)
+		}
+	}
+
+	if len(parts) > 1 {
+		for i, sql := range parts {
+			if needsParens(sql) {
+				parts[i] = "(" + sql + ")"
+			}
+		}
+	}
+
+	return strings.Join(parts, " "+c.operator+" ")
+}
+
+func (c *not) ToSQL() (string, []interface{}) {
+	var args []interface{}
+	sql := c.render(func(condition Condition) string {
+		sql, conditionArgs := condition.ToSQL()
+		args = conditionArgs
+		return sql
+	})
+	if sql == "" {
+		return "", nil
+	}
+
+	return sql, args
+}
+
+func (c *not) render(part func(Condition) string) string {
+	if c.condition == nil {
+		return ""
+	}
+
+	sql := part(c.condition)
+	if sql == "" {
+		return ""
+	}
+
+	return "NOT (" + sql + ")"
+}
+
+func toCondition(condition interface{}) (Condition, bool) {
+	switch c := condition.(type) {
+	case nil:
+		return nil, true
+	case Condition:
+		return c, true
+	case string:
+		return raw(c), true
+	}
+
+	return nil, false
+}
+
+func flatten(values []interface{}) []interface{} {
+	if len(values) != 1 {
+		return values
+	}
+
+	if flat, ok := sliceValues(values[0]); ok {
+		return flat
+	}
+
+	return values
+}
+
+func sliceValues(value interface{}) ([]interface{}, bool) {
+	if _, ok := value.(driver.Valuer); ok {
+		return nil, false
+	}
+
+	v := reflect.ValueOf(value)
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+
+	return values, true
+}
+
+func needsParens(sql string) bool {
+	var (
+		depth int
+		quote byte
+	)
+	upper := strings.ToUpper(sql)
+	for i := 0; i < len(upper); i++ {
+		c := upper[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && c == ' ':
+			if strings.HasPrefix(upper[i:], " AND ") || strings.HasPrefix(upper[i:], " OR ") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
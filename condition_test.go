@@ -0,0 +1,91 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionToSQL(t *testing.T) {
+	tests := []struct {
+		condition Condition
+		sql       string
+		args      []interface{}
+	}{
+		{Eq("id", 1), "id = ?", []interface{}{1}},
+		{NotEq("id", 1), "id <> ?", []interface{}{1}},
+		{Gt("age", 18), "age > ?", []interface{}{18}},
+		{Gte("age", 18), "age >= ?", []interface{}{18}},
+		{Lt("age", 65), "age < ?", []interface{}{65}},
+		{Lte("age", 65), "age <= ?", []interface{}{65}},
+		{Like("email", "%@email.com"), "email LIKE ?", []interface{}{"%@email.com"}},
+		{In("id", 1, 2, 3), "id IN (?, ?, ?)", []interface{}{1, 2, 3}},
+		{In("id", []int{1, 2}), "id IN (?, ?)", []interface{}{1, 2}},
+		{In("id"), "1=0", nil},
+		{NotIn("id", 1, 2), "id NOT IN (?, ?)", []interface{}{1, 2}},
+		{NotIn("id", []int{}), "1=1", nil},
+		{Between("age", 18, 65), "age BETWEEN ? AND ?", []interface{}{18, 65}},
+		{IsNull("deleted_at"), "deleted_at IS NULL", nil},
+		{IsNotNull("deleted_at"), "deleted_at IS NOT NULL", nil},
+		{Expr("LOWER(email) = ?", "a"), "LOWER(email) = ?", []interface{}{"a"}},
+		{Not(Eq("id", 1)), "NOT (id = ?)", []interface{}{1}},
+		{And(), "", nil},
+		{And(Eq("a", 1)), "a = ?", []interface{}{1}},
+		{And(Eq("a", 1), Eq("b", 2)), "a = ? AND b = ?", []interface{}{1, 2}},
+		{
+			Or(And(Eq("a", 1), Eq("b", 2)), Between("c", 3, 4)),
+			"(a = ? AND b = ?) OR (c BETWEEN ? AND ?)",
+			[]interface{}{1, 2, 3, 4},
+		},
+		{
+			And(Eq("a", 1), Or(Eq("b", 2), IsNull("b")), Not(In("c", 5, 6))),
+			"a = ? AND (b = ? OR b IS NULL) AND NOT (c IN (?, ?))",
+			[]interface{}{1, 2, 5, 6},
+		},
+		{And(Expr("a = 1 OR b = 2"), Expr("c = 3")), "(a = 1 OR b = 2) AND c = 3", nil},
+		{And(Expr("a = 'x AND y'"), Expr("f(a AND b)")), "a = 'x AND y' AND f(a AND b)", nil},
+	}
+
+	for _, test := range tests {
+		sql, args := test.condition.ToSQL()
+		assert.Equal(t, test.sql, sql)
+		assert.Equal(t, test.args, args)
+	}
+}
+
+func TestConditionBuilder(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+
+	q := New(reflectT).
+		Select().
+		Where("email = ?").
+		AndWhere(In("id", 1, 2)).
+		OrWhere(And(Gt("id", 10), Lt("id", 20))).
+		SetParameters("test@email.com").
+		GetQuery()
+
+	assert.Equal(t, "SELECT id, email FROM users WHERE (email = ? AND id IN (?, ?)) OR (id > ? AND id < ?)", q.GetSQL())
+	assert.Equal(t, []interface{}{"test@email.com", 1, 2, 10, 20}, q.GetParameters())
+
+	q = New(reflectT).
+		SetDialect(PostgreSQL).
+		Count("id").
+		GroupBy("email").
+		Having(Gt("COUNT(id)", 1)).
+		Where(IsNotNull("email")).
+		GetQuery()
+
+	assert.Equal(t, `SELECT COUNT(id) FROM "users" WHERE email IS NOT NULL GROUP BY email HAVING COUNT(id) > $1`, q.GetSQL())
+	assert.Equal(t, []interface{}{1}, q.GetParameters())
+
+	b := New(reflectT).Select().Where(42)
+	assert.Nil(t, b.(*builder).where)
+	assert.EqualError(t, b.(*builder).err, "builder: Unsupported condition type int!")
+}
@@ -0,0 +1,483 @@
+package goquery
+
+import (
+	"strconv"
+	"strings"
+)
+
+type (
+	Dialect interface {
+		Placeholder(n int) string
+		Quote(identifier string) string
+		LimitOffset(limit, offset int64, ordered bool) string
+		NullsOrder() bool
+		MaxParameters() int
+		InsertIdRange() InsertIdRange
+		WriteLimit() bool
+		Returning(columns []string, deleted bool) (output, returning string)
+		Upsert(table string, columns []string, rows [][]string, target, update []string) string
+	}
+
+	InsertIdRange int
+
+	generic struct{}
+	mysql   struct {
+		consecutiveIds bool
+	}
+	postgres  struct{}
+	sqlite    struct{}
+	sqlserver struct{}
+	oracle    struct{}
+)
+
+var (
+	Generic             Dialect = generic{}
+	MySQL               Dialect = mysql{}
+	MySQLConsecutiveIds Dialect = mysql{consecutiveIds: true}
+	PostgreSQL          Dialect = postgres{}
+	SQLite              Dialect = sqlite{}
+	SQLServer           Dialect = sqlserver{}
+	Oracle              Dialect = oracle{}
+
+	DefaultDialect = Generic
+)
+
+const (
+	InsertIdUnknown InsertIdRange = iota
+	InsertIdFirst
+	InsertIdLast
+)
+
+func (generic) Placeholder(n int) string {
+	return "?"
+}
+
+func (generic) Quote(identifier string) string {
+	return identifier
+}
+
+func (generic) LimitOffset(limit, offset int64, ordered bool) string {
+	return limitOffset(limit, offset, "")
+}
+
+func (generic) NullsOrder() bool {
+	return false
+}
+
+func (generic) MaxParameters() int {
+	return 999
+}
+
+func (generic) InsertIdRange() InsertIdRange {
+	return InsertIdUnknown
+}
+
+func (generic) WriteLimit() bool {
+	return false
+}
+
+func (d generic) Upsert(table string, columns []string, rows [][]string, target, update []string) string {
+	return onConflict(d, table, columns, rows, target, update)
+}
+
+func (generic) Returning(columns []string, deleted bool) (string, string) {
+	return "", ""
+}
+
+func (mysql) Placeholder(n int) string {
+	return "?"
+}
+
+func (mysql) Quote(identifier string) string {
+	return quoteIdentifier(identifier, "`", "`")
+}
+
+func (mysql) LimitOffset(limit, offset int64, ordered bool) string {
+	return limitOffset(limit, offset, "18446744073709551615")
+}
+
+func (mysql) NullsOrder() bool {
+	return false
+}
+
+func (mysql) MaxParameters() int {
+	return 65535
+}
+
+func (d mysql) InsertIdRange() InsertIdRange {
+	if d.consecutiveIds {
+		return InsertIdFirst
+	}
+
+	return InsertIdUnknown
+}
+
+func (mysql) WriteLimit() bool {
+	return true
+}
+
+func (d mysql) Upsert(table string, columns []string, rows [][]string, target, update []string) string {
+	return onDuplicateKey(d, table, columns, rows, target, update)
+}
+
+func (mysql) Returning(columns []string, deleted bool) (string, string) {
+	return "", ""
+}
+
+func (postgres) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (postgres) Quote(identifier string) string {
+	return quoteIdentifier(identifier, `"`, `"`)
+}
+
+func (postgres) LimitOffset(limit, offset int64, ordered bool) string {
+	return limitOffset(limit, offset, "")
+}
+
+func (postgres) NullsOrder() bool {
+	return true
+}
+
+func (postgres) MaxParameters() int {
+	return 65535
+}
+
+func (postgres) InsertIdRange() InsertIdRange {
+	return InsertIdUnknown
+}
+
+func (postgres) WriteLimit() bool {
+	return false
+}
+
+func (d postgres) Upsert(table string, columns []string, rows [][]string, target, update []string) string {
+	return onConflict(d, table, columns, rows, target, update)
+}
+
+func (d postgres) Returning(columns []string, deleted bool) (string, string) {
+	return "", returning(d, columns)
+}
+
+func (sqlite) Placeholder(n int) string {
+	return "?"
+}
+
+func (sqlite) Quote(identifier string) string {
+	return quoteIdentifier(identifier, `"`, `"`)
+}
+
+func (sqlite) LimitOffset(limit, offset int64, ordered bool) string {
+	return limitOffset(limit, offset, "-1")
+}
+
+func (sqlite) NullsOrder() bool {
+	return true
+}
+
+func (sqlite) MaxParameters() int {
+	return 32766
+}
+
+func (sqlite) InsertIdRange() InsertIdRange {
+	return InsertIdLast
+}
+
+func (sqlite) WriteLimit() bool {
+	return false
+}
+
+func (d sqlite) Upsert(table string, columns []string, rows [][]string, target, update []string) string {
+	return onConflict(d, table, columns, rows, target, update)
+}
+
+func (d sqlite) Returning(columns []string, deleted bool) (string, string) {
+	return "", returning(d, columns)
+}
+
+func (sqlserver) Placeholder(n int) string {
+	return "@p" + strconv.Itoa(n)
+}
+
+func (sqlserver) Quote(identifier string) string {
+	return quoteIdentifier(identifier, "[", "]")
+}
+
+func (sqlserver) LimitOffset(limit, offset int64, ordered bool) string {
+	if limit <= 0 && offset <= 0 {
+		return ""
+	}
+
+	var sql string
+	if !ordered {
+		sql = " ORDER BY (SELECT NULL)"
+	}
+
+	return sql + offsetFetch(limit, offset)
+}
+
+func (sqlserver) NullsOrder() bool {
+	return false
+}
+
+func (sqlserver) MaxParameters() int {
+	return 2100
+}
+
+func (sqlserver) InsertIdRange() InsertIdRange {
+	return InsertIdUnknown
+}
+
+func (sqlserver) WriteLimit() bool {
+	return false
+}
+
+func (d sqlserver) Upsert(table string, columns []string, rows [][]string, target, update []string) string {
+	source := "(VALUES " + valuesList(rows) + ") AS s (" + quoteAll(d, columns, "") + ")"
+
+	return merge(d, table, source, columns, target, update) + ";"
+}
+
+func (d sqlserver) Returning(columns []string, deleted bool) (string, string) {
+	if len(columns) == 0 {
+		return "", ""
+	}
+
+	prefix := "INSERTED."
+	if deleted {
+		prefix = "DELETED."
+	}
+
+	return " OUTPUT " + quoteAll(d, columns, prefix), ""
+}
+
+func (oracle) Placeholder(n int) string {
+	return ":" + strconv.Itoa(n)
+}
+
+func (oracle) Quote(identifier string) string {
+	return quoteIdentifier(identifier, `"`, `"`)
+}
+
+func (oracle) LimitOffset(limit, offset int64, ordered bool) string {
+	if limit <= 0 && offset <= 0 {
+		return ""
+	}
+
+	return offsetFetch(limit, offset)
+}
+
+func (oracle) NullsOrder() bool {
+	return true
+}
+
+func (oracle) MaxParameters() int {
+	return 65535
+}
+
+func (oracle) InsertIdRange() InsertIdRange {
+	return InsertIdUnknown
+}
+
+func (oracle) WriteLimit() bool {
+	return false
+}
+
+func (d oracle) Upsert(table string, columns []string, rows [][]string, target, update []string) string {
+	selects := make([]string, len(rows))
+	for i, row := range rows {
+		values := make([]string, len(row))
+		for j, value := range row {
+			values[j] = value + " AS " + d.Quote(columns[j])
+		}
+		selects[i] = "SELECT " + strings.Join(values, ", ") + " FROM DUAL"
+	}
+	source := "(" + strings.Join(selects, " UNION ALL ") + ") s"
+
+	return merge(d, table, source, columns, target, update)
+}
+
+func (oracle) Returning(columns []string, deleted bool) (string, string) {
+	return "", ""
+}
+
+func insertValues(d Dialect, table string, columns []string, rows [][]string) string {
+	return insertInto(d, table, columns) + " VALUES " + valuesList(rows)
+}
+
+func insertInto(d Dialect, table string, columns []string) string {
+	return "INSERT INTO " + d.Quote(table) + " (" + quoteAll(d, columns, "") + ")"
+}
+
+func valuesList(rows [][]string) string {
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		values[i] = "(" + strings.Join(row, ", ") + ")"
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func onConflict(d Dialect, table string, columns []string, rows [][]string, target, update []string) string {
+	sql := insertValues(d, table, columns, rows) + " ON CONFLICT"
+	if len(target) > 0 {
+		sql += " (" + quoteAll(d, target, "") + ")"
+	}
+	if len(update) == 0 {
+		return sql + " DO NOTHING"
+	}
+
+	sets := make([]string, len(update))
+	for i, col := range update {
+		sets[i] = d.Quote(col) + " = EXCLUDED." + d.Quote(col)
+	}
+
+	return sql + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+func onDuplicateKey(d Dialect, table string, columns []string, rows [][]string, target, update []string) string {
+	sql := insertValues(d, table, columns, rows) + " ON DUPLICATE KEY UPDATE "
+	if len(update) == 0 {
+		noop := columns[0]
+		if len(target) > 0 {
+			noop = target[0]
+		}
+		return sql + d.Quote(noop) + " = " + d.Quote(noop)
+	}
+
+	sets := make([]string, len(update))
+	for i, col := range update {
+		sets[i] = d.Quote(col) + " = VALUES(" + d.Quote(col) + ")"
+	}
+
+	return sql + strings.Join(sets, ", ")
+}
+
+func merge(d Dialect, table, source string, columns, target, update []string) string {
+	on := make([]string, len(target))
+	for i, col := range target {
+		on[i] = "t." + d.Quote(col) + " = s." + d.Quote(col)
+	}
+
+	sql := "MERGE INTO " + d.Quote(table) + " t USING " + source + " ON (" + strings.Join(on, " AND ") + ")"
+	if len(update) > 0 {
+		sets := make([]string, len(update))
+		for i, col := range update {
+			sets[i] = "t." + d.Quote(col) + " = s." + d.Quote(col)
+		}
+		sql += " WHEN MATCHED THEN UPDATE SET " + strings.Join(sets, ", ")
+	}
+
+	return sql + " WHEN NOT MATCHED THEN INSERT (" + quoteAll(d, columns, "") + ") VALUES (" + quoteAll(d, columns, "s.") + ")"
+}
+
+func quoteAll(d Dialect, columns []string, prefix string) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = prefix + d.Quote(col)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+func returning(d Dialect, columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+
+	return " RETURNING " + quoteAll(d, columns, "")
+}
+
+func limitOffset(limit, offset int64, unlimited string) string {
+	var sql string
+	if limit > 0 {
+		sql += " LIMIT " + strconv.FormatInt(limit, 10)
+	} else if offset > 0 && unlimited != "" {
+		sql += " LIMIT " + unlimited
+	}
+
+	if offset > 0 {
+		sql += " OFFSET " + strconv.FormatInt(offset, 10)
+	}
+
+	return sql
+}
+
+func offsetFetch(limit, offset int64) string {
+	sql := " OFFSET " + strconv.FormatInt(offset, 10) + " ROWS"
+	if limit > 0 {
+		sql += " FETCH NEXT " + strconv.FormatInt(limit, 10) + " ROWS ONLY"
+	}
+
+	return sql
+}
+
+func quoteIdentifier(identifier, open, close string) string {
+	if identifier == "" || identifier == "*" || strings.HasPrefix(identifier, open) {
+		return identifier
+	}
+
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = open + strings.Replace(part, close, close+close, -1) + close
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c != '_' && c != '.' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func rebind(d Dialect, sql string) string {
+	if d.Placeholder(1) == "?" {
+		return sql
+	}
+
+	return replacePlaceholders(sql, bracketQuotes(d), d.Placeholder)
+}
+
+func bracketQuotes(d Dialect) bool {
+	return d.Quote("x") == "[x]"
+}
+
+func replacePlaceholders(sql string, brackets bool, placeholder func(n int) string) string {
+	var (
+		buf   strings.Builder
+		n     int
+		quote byte
+	)
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '[' && brackets:
+			quote = ']'
+		case c == '?':
+			n++
+			buf.WriteString(placeholder(n))
+			continue
+		}
+		buf.WriteByte(c)
+	}
+
+	return buf.String()
+}
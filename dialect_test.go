@@ -0,0 +1,269 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectSQL(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+
+	tests := []struct {
+		dialect Dialect
+		sql     map[string]string
+	}{
+		{Generic, map[string]string{
+			"select": "SELECT id, email FROM users WHERE id = ? AND email = ? LIMIT 10 OFFSET 5",
+			"count":  "SELECT COUNT(id) FROM users WHERE id = ? AND email = ?",
+			"insert": "INSERT INTO users (email) VALUES (?)",
+			"save":   "UPDATE users SET email=? WHERE id=?",
+			"delete": "DELETE FROM users WHERE id = ? AND email = ?",
+		}},
+		{MySQL, map[string]string{
+			"select": "SELECT `id`, `email` FROM `users` WHERE id = ? AND email = ? LIMIT 10 OFFSET 5",
+			"count":  "SELECT COUNT(id) FROM `users` WHERE id = ? AND email = ?",
+			"insert": "INSERT INTO `users` (`email`) VALUES (?)",
+			"save":   "UPDATE `users` SET `email`=? WHERE `id`=?",
+			"delete": "DELETE FROM `users` WHERE id = ? AND email = ?",
+		}},
+		{PostgreSQL, map[string]string{
+			"select": `SELECT "id", "email" FROM "users" WHERE id = $1 AND email = $2 LIMIT 10 OFFSET 5`,
+			"count":  `SELECT COUNT(id) FROM "users" WHERE id = $1 AND email = $2`,
+			"insert": `INSERT INTO "users" ("email") VALUES ($1)`,
+			"save":   `UPDATE "users" SET "email"=$1 WHERE "id"=$2`,
+			"delete": `DELETE FROM "users" WHERE id = $1 AND email = $2`,
+		}},
+		{SQLite, map[string]string{
+			"select": `SELECT "id", "email" FROM "users" WHERE id = ? AND email = ? LIMIT 10 OFFSET 5`,
+			"count":  `SELECT COUNT(id) FROM "users" WHERE id = ? AND email = ?`,
+			"insert": `INSERT INTO "users" ("email") VALUES (?)`,
+			"save":   `UPDATE "users" SET "email"=? WHERE "id"=?`,
+			"delete": `DELETE FROM "users" WHERE id = ? AND email = ?`,
+		}},
+		{SQLServer, map[string]string{
+			"select": "SELECT [id], [email] FROM [users] WHERE id = @p1 AND email = @p2 ORDER BY (SELECT NULL) OFFSET 5 ROWS FETCH NEXT 10 ROWS ONLY",
+			"count":  "SELECT COUNT(id) FROM [users] WHERE id = @p1 AND email = @p2",
+			"insert": "INSERT INTO [users] ([email]) VALUES (@p1)",
+			"save":   "UPDATE [users] SET [email]=@p1 WHERE [id]=@p2",
+			"delete": "DELETE FROM [users] WHERE id = @p1 AND email = @p2",
+		}},
+		{Oracle, map[string]string{
+			"select": `SELECT "id", "email" FROM "users" WHERE id = :1 AND email = :2 OFFSET 5 ROWS FETCH NEXT 10 ROWS ONLY`,
+			"count":  `SELECT COUNT(id) FROM "users" WHERE id = :1 AND email = :2`,
+			"insert": `INSERT INTO "users" ("email") VALUES (:1)`,
+			"save":   `UPDATE "users" SET "email"=:1 WHERE "id"=:2`,
+			"delete": `DELETE FROM "users" WHERE id = :1 AND email = :2`,
+		}},
+	}
+
+	for _, test := range tests {
+		qBuilder := New(reflect.TypeOf(user{})).SetDialect(test.dialect)
+
+		sql := qBuilder.Select().Where("id = ?").AndWhere("email = ?").Limit(10).Offset(5).GetQuery().GetSQL()
+		assert.Equal(t, test.sql["select"], sql)
+
+		sql = qBuilder.Count("id").GetQuery().GetSQL()
+		assert.Equal(t, test.sql["count"], sql)
+
+		qBuilder.Reset()
+		qBuilder.(*builder).statement = "insert"
+		assert.Equal(t, test.sql["insert"], qBuilder.GetQuery().GetSQL())
+
+		qBuilder.(*builder).statement = "save"
+		assert.Equal(t, test.sql["save"], qBuilder.GetQuery().GetSQL())
+
+		sql = qBuilder.Delete().Where("id = ?").AndWhere("email = ?").GetQuery().GetSQL()
+		assert.Equal(t, test.sql["delete"], sql)
+	}
+}
+
+func TestDialectLimitOffset(t *testing.T) {
+	tests := []struct {
+		dialect       Dialect
+		limit, offset int64
+		ordered       bool
+		sql           string
+	}{
+		{Generic, 0, 0, false, ""},
+		{Generic, 0, 5, false, " OFFSET 5"},
+		{MySQL, 0, 5, false, " LIMIT 18446744073709551615 OFFSET 5"},
+		{PostgreSQL, 10, 0, false, " LIMIT 10"},
+		{SQLite, 0, 5, false, " LIMIT -1 OFFSET 5"},
+		{SQLServer, 0, 0, false, ""},
+		{SQLServer, 10, 0, true, " OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{SQLServer, 0, 5, false, " ORDER BY (SELECT NULL) OFFSET 5 ROWS"},
+		{Oracle, 10, 0, false, " OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.sql, test.dialect.LimitOffset(test.limit, test.offset, test.ordered))
+	}
+}
+
+func TestDialectInsert(t *testing.T) {
+	tests := []struct {
+		dialect   Dialect
+		max       int
+		idRange   InsertIdRange
+		output    string
+		deleted   string
+		returning string
+	}{
+		{Generic, 999, InsertIdUnknown, "", "", ""},
+		{MySQL, 65535, InsertIdUnknown, "", "", ""},
+		{MySQLConsecutiveIds, 65535, InsertIdFirst, "", "", ""},
+		{PostgreSQL, 65535, InsertIdUnknown, "", "", ` RETURNING "id", "created_at"`},
+		{SQLite, 32766, InsertIdLast, "", "", ` RETURNING "id", "created_at"`},
+		{SQLServer, 2100, InsertIdUnknown, " OUTPUT INSERTED.[id], INSERTED.[created_at]", " OUTPUT DELETED.[id], DELETED.[created_at]", ""},
+		{Oracle, 65535, InsertIdUnknown, "", "", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.max, test.dialect.MaxParameters())
+		assert.Equal(t, test.idRange, test.dialect.InsertIdRange())
+		output, returning := test.dialect.Returning([]string{"id", "created_at"}, false)
+		assert.Equal(t, test.output, output)
+		assert.Equal(t, test.returning, returning)
+
+		output, returning = test.dialect.Returning([]string{"id", "created_at"}, true)
+		assert.Equal(t, test.deleted, output)
+		assert.Equal(t, test.returning, returning)
+
+		output, returning = test.dialect.Returning(nil, false)
+		assert.Equal(t, "", output+returning)
+	}
+}
+
+func TestDialectUpsert(t *testing.T) {
+	var (
+		columns = []string{"email", "name"}
+		rows    = [][]string{{"?", "?"}, {"?", "?"}}
+		target  = []string{"email"}
+		update  = []string{"name"}
+	)
+
+	tests := []struct {
+		dialect         Dialect
+		update, nothing string
+	}{
+		{PostgreSQL,
+			`INSERT INTO "users" ("email", "name") VALUES (?, ?), (?, ?) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name"`,
+			`INSERT INTO "users" ("email", "name") VALUES (?, ?), (?, ?) ON CONFLICT ("email") DO NOTHING`},
+		{SQLite,
+			`INSERT INTO "users" ("email", "name") VALUES (?, ?), (?, ?) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name"`,
+			`INSERT INTO "users" ("email", "name") VALUES (?, ?), (?, ?) ON CONFLICT ("email") DO NOTHING`},
+		{MySQL,
+			"INSERT INTO `users` (`email`, `name`) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)",
+			"INSERT INTO `users` (`email`, `name`) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE `email` = `email`"},
+		{SQLServer,
+			"MERGE INTO [users] t USING (VALUES (?, ?), (?, ?)) AS s ([email], [name]) ON (t.[email] = s.[email]) " +
+				"WHEN MATCHED THEN UPDATE SET t.[name] = s.[name] WHEN NOT MATCHED THEN INSERT ([email], [name]) VALUES (s.[email], s.[name]);",
+			"MERGE INTO [users] t USING (VALUES (?, ?), (?, ?)) AS s ([email], [name]) ON (t.[email] = s.[email]) " +
+				"WHEN NOT MATCHED THEN INSERT ([email], [name]) VALUES (s.[email], s.[name]);"},
+		{Oracle,
+			`MERGE INTO "users" t USING (SELECT ? AS "email", ? AS "name" FROM DUAL UNION ALL SELECT ? AS "email", ? AS "name" FROM DUAL) s ON (t."email" = s."email") ` +
+				`WHEN MATCHED THEN UPDATE SET t."name" = s."name" WHEN NOT MATCHED THEN INSERT ("email", "name") VALUES (s."email", s."name")`,
+			`MERGE INTO "users" t USING (SELECT ? AS "email", ? AS "name" FROM DUAL UNION ALL SELECT ? AS "email", ? AS "name" FROM DUAL) s ON (t."email" = s."email") ` +
+				`WHEN NOT MATCHED THEN INSERT ("email", "name") VALUES (s."email", s."name")`},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.update, test.dialect.Upsert("users", columns, rows, target, update))
+		assert.Equal(t, test.nothing, test.dialect.Upsert("users", columns, rows, target, nil))
+	}
+}
+
+func TestDialectQuote(t *testing.T) {
+	assert.Equal(t, "users", Generic.Quote("users"))
+	assert.Equal(t, "`public`.`users`", MySQL.Quote("public.users"))
+	assert.Equal(t, `"a""b"`, PostgreSQL.Quote(`a"b`))
+	assert.Equal(t, "[u].*", SQLServer.Quote("u.*"))
+	assert.Equal(t, "*", SQLite.Quote("*"))
+}
+
+func TestRebind(t *testing.T) {
+	assert.Equal(t, "a = ? AND b = '?'", rebind(MySQL, "a = ? AND b = '?'"))
+	assert.Equal(t, `a = $1 AND b = '?' AND "c?" = $2`, rebind(PostgreSQL, `a = ? AND b = '?' AND "c?" = ?`))
+	assert.Equal(t, "a = @p1 AND [b?] = @p2", rebind(SQLServer, "a = ? AND [b?] = ?"))
+	assert.Equal(t, "a = :1 AND b = 'it''s ?' AND c = :2", rebind(Oracle, "a = ? AND b = 'it''s ?' AND c = ?"))
+}
+
+func TestDialectNullsOrder(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+
+	tests := []struct {
+		dialect Dialect
+		sql     string
+	}{
+		{PostgreSQL, `SELECT "id" FROM "users" ORDER BY email ASC NULLS FIRST, id DESC NULLS LAST`},
+		{MySQL, "SELECT `id` FROM `users` ORDER BY CASE WHEN email IS NULL THEN 0 ELSE 1 END, email ASC, CASE WHEN id IS NULL THEN 1 ELSE 0 END, id DESC"},
+		{SQLServer, "SELECT [id] FROM [users] ORDER BY CASE WHEN email IS NULL THEN 0 ELSE 1 END, email ASC, CASE WHEN id IS NULL THEN 1 ELSE 0 END, id DESC"},
+	}
+
+	for _, test := range tests {
+		sql := New(reflect.TypeOf(user{})).
+			SetDialect(test.dialect).
+			Select("id").
+			OrderBy("email", "ASC NULLS FIRST").
+			AddOrderBy("id", "DESC NULLS LAST").
+			GetQuery().
+			GetSQL()
+
+		assert.Equal(t, test.sql, sql)
+	}
+}
+
+func TestDialectJoin(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+
+	q := New(reflect.TypeOf(user{})).
+		SetDialect(PostgreSQL).
+		Select().
+		Join("posts", "p", "p.user_id = users.id AND p.status = ?", "published").
+		Where("users.id > ?").
+		SetParameters(10).
+		GetQuery()
+
+	assert.Equal(t, `SELECT "users"."id", "users"."email" FROM "users" JOIN "posts" p ON p.user_id = users.id AND p.status = $1 WHERE users.id > $2`, q.GetSQL())
+	assert.Equal(t, []interface{}{"published", 10}, q.GetParameters())
+
+	q = New(reflect.TypeOf(user{})).SetDialect(PostgreSQL).Select().From("users u").Join("posts", "p", "p.user_id = u.id").GetQuery()
+	assert.Equal(t, `SELECT "u"."id", "u"."email" FROM users u JOIN "posts" p ON p.user_id = u.id`, q.GetSQL())
+
+	q = New(reflect.TypeOf(user{})).SetDialect(PostgreSQL).Select().From("(SELECT * FROM users)").Join("posts", "p", "p.user_id = users.id").GetQuery()
+	assert.Equal(t, `SELECT "users"."id", "users"."email" FROM (SELECT * FROM users) JOIN "posts" p ON p.user_id = users.id`, q.GetSQL())
+}
+
+func TestDialectBrackets(t *testing.T) {
+	type (
+		user struct {
+			Id   int64    `json:"id" column:"id"`
+			Tags []string `json:"tags" column:"tags"`
+		}
+	)
+
+	q := New(reflect.TypeOf(user{})).SetDialect(PostgreSQL).Select("id").Where("tags[?] = ? AND id = :id", 1, "a").SetNamedParameters(map[string]interface{}{"id": 2}).GetQuery()
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE tags[$1] = $2 AND id = $3`, q.GetSQL())
+	assert.Equal(t, []interface{}{1, "a", 2}, q.GetParameters())
+
+	q = New(reflect.TypeOf(user{})).SetDialect(SQLServer).Select("id").Where("[what?] = ? AND [:id] = :id", "a").SetNamedParameters(map[string]interface{}{"id": 2}).GetQuery()
+	assert.Equal(t, "SELECT [id] FROM [users] WHERE [what?] = @p1 AND [:id] = @p2", q.GetSQL())
+	assert.Equal(t, []interface{}{"a", 2}, q.GetParameters())
+}
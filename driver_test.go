@@ -0,0 +1,156 @@
+package goquery
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+type (
+	testDB struct {
+		mu        sync.Mutex
+		queries   []string
+		args      [][]driver.Value
+		commits   int
+		rollbacks int
+		lastId    int64
+
+		rows   func(query string, args []driver.Value) (*testRows, error)
+		result func(query string, args []driver.Value) (driver.Result, error)
+	}
+
+	testDriver struct{}
+
+	testConn struct {
+		db *testDB
+	}
+
+	testStmt struct {
+		conn  *testConn
+		query string
+	}
+
+	testTx struct {
+		conn *testConn
+	}
+
+	testRows struct {
+		columns []string
+		values  [][]driver.Value
+	}
+
+	testResult int64
+)
+
+func openTestDB(db *testDB) *sql.DB {
+	return sql.OpenDB(db)
+}
+
+func (db *testDB) Connect(context.Context) (driver.Conn, error) {
+	return &testConn{db}, nil
+}
+
+func (db *testDB) Driver() driver.Driver {
+	return testDriver{}
+}
+
+func (db *testDB) record(query string, args []driver.Value) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.queries = append(db.queries, query)
+	db.args = append(db.args, args)
+}
+
+func (testDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("test driver: use openTestDB")
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{c, query}, nil
+}
+
+func (c *testConn) Close() error {
+	return nil
+}
+
+func (c *testConn) Begin() (driver.Tx, error) {
+	return &testTx{c}, nil
+}
+
+func (s *testStmt) Close() error {
+	return nil
+}
+
+func (s *testStmt) NumInput() int {
+	return -1
+}
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	db.record(s.query, args)
+	if db.result != nil {
+		return db.result(s.query, args)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.lastId++
+
+	return testResult(db.lastId), nil
+}
+
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.conn.db
+	db.record(s.query, args)
+	if db.rows == nil {
+		return &testRows{}, nil
+	}
+
+	return db.rows(s.query, args)
+}
+
+func (tx *testTx) Commit() error {
+	tx.conn.db.mu.Lock()
+	defer tx.conn.db.mu.Unlock()
+	tx.conn.db.commits++
+
+	return nil
+}
+
+func (tx *testTx) Rollback() error {
+	tx.conn.db.mu.Lock()
+	defer tx.conn.db.mu.Unlock()
+	tx.conn.db.rollbacks++
+
+	return nil
+}
+
+func (r *testRows) Columns() []string {
+	return r.columns
+}
+
+func (r *testRows) Close() error {
+	return nil
+}
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+
+	return nil
+}
+
+func (r testResult) LastInsertId() (int64, error) {
+	return int64(r), nil
+}
+
+func (r testResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
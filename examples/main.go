@@ -28,11 +28,11 @@ func main() {
 	fmt.Println(data1, err)
 
 	//SELECT SINGLE ROW
-	data2, err := builder.Select().Where("id = ?").SetParameters(1).GetQuery().GetResult(conn)
+	data2, err := builder.Select().Where("id = ?", 1).GetQuery().GetResult(conn)
 	fmt.Println(data2, err)
 
 	//REMOVE
-	data3, err := builder.Delete().Where("id IN (?)").SetParameters([]int{1, 2}).GetQuery().Execute(conn)
+	data3, err := builder.Delete().Where("id IN (?)", []int{1, 2}).GetQuery().Execute(conn)
 	fmt.Println(data3, err)
 
 	//ADD/UPDATE
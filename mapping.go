@@ -0,0 +1,287 @@
+package goquery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type (
+	columnOptions struct {
+		pk          bool
+		auto        bool
+		readonly    bool
+		omitempty   bool
+		version     bool
+		softDelete  bool
+		hasDefault  bool
+		defaultExpr string
+	}
+
+	metadata struct {
+		table      string
+		fields     []mappedField
+		byName     map[string]int
+		pk         []int
+		keys       []string
+		auto       int
+		version    int
+		softDelete int
+		hasAuto    bool
+		generated  bool
+		extra      int
+		err        error
+	}
+
+	mappedField struct {
+		index   int
+		column  string
+		options columnOptions
+	}
+
+	writeField struct {
+		index  int
+		column string
+		expr   string
+	}
+)
+
+var registry sync.Map
+
+func parseColumn(f reflect.StructField) (string, columnOptions) {
+	var (
+		parts   = strings.Split(f.Tag.Get("column"), ",")
+		options columnOptions
+	)
+	for i := 1; i < len(parts); i++ {
+		option := strings.TrimSpace(parts[i])
+		switch name := strings.ToLower(option); {
+		case name == "pk":
+			options.pk = true
+		case name == "auto":
+			options.auto = true
+		case name == "readonly":
+			options.readonly = true
+		case name == "omitempty":
+			options.omitempty = true
+		case name == "version":
+			options.version = true
+		case name == "softdelete":
+			options.softDelete = true
+		case name == "default":
+			options.hasDefault = true
+		case strings.HasPrefix(name, "default="):
+			options.hasDefault = true
+			options.defaultExpr = strings.TrimSpace(strings.Join(append([]string{option[len("default="):]}, parts[i+1:]...), ","))
+			i = len(parts)
+		}
+	}
+
+	return strings.TrimSpace(parts[0]), options
+}
+
+func getMetadata(t reflect.Type) *metadata {
+	if m, ok := registry.Load(t); ok {
+		return m.(*metadata)
+	}
+
+	m, _ := registry.LoadOrStore(t, newMetadata(t))
+
+	return m.(*metadata)
+}
+
+func resetMetadata() {
+	registry.Range(func(key, _ interface{}) bool {
+		registry.Delete(key)
+		return true
+	})
+}
+
+func newMetadata(t reflect.Type) *metadata {
+	m := &metadata{
+		table:      tableName(t),
+		byName:     make(map[string]int),
+		auto:       -1,
+		version:    -1,
+		softDelete: -1,
+		extra:      -1,
+	}
+
+	id := -1
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		col := getColumn(f)
+		if col == "" {
+			if f.Tag.Get("column") == "*" && f.Type == reflect.TypeOf(map[string]interface{}{}) {
+				m.extra = i
+			}
+			continue
+		}
+
+		_, options := parseColumn(f)
+		m.fields = append(m.fields, mappedField{i, col, options})
+		if _, ok := m.byName[strings.ToLower(col)]; !ok {
+			m.byName[strings.ToLower(col)] = i
+		}
+
+		switch {
+		case options.pk:
+			m.pk = append(m.pk, i)
+		case id < 0 && strings.EqualFold(col, "id"):
+			id = i
+		}
+		if options.version && m.version < 0 {
+			if isInteger(f.Type) {
+				m.version = i
+			} else if m.err == nil {
+				m.err = fmt.Errorf("query: Unsupported version type %s for %s.%s!", f.Type, t.Name(), f.Name)
+			}
+		}
+		if options.softDelete && m.softDelete < 0 {
+			m.softDelete = i
+		}
+		if options.auto && !m.hasAuto {
+			m.hasAuto = true
+			if isInteger(f.Type) {
+				m.auto = i
+			}
+		}
+	}
+	if len(m.pk) == 0 && id >= 0 {
+		m.pk = append(m.pk, id)
+		if !m.hasAuto && isInteger(t.Field(id).Type) {
+			m.auto = id
+		}
+	}
+	for _, k := range m.pk {
+		m.keys = append(m.keys, getColumn(t.Field(k)))
+	}
+	m.generated = len(m.pk) == 1 && (m.auto == m.pk[0] || m.field(m.pk[0]).options.auto)
+
+	return m
+}
+
+func (m *metadata) field(i int) mappedField {
+	for _, f := range m.fields {
+		if f.index == i {
+			return f
+		}
+	}
+
+	return mappedField{index: -1}
+}
+
+func (m *metadata) isKey(i int) bool {
+	for _, k := range m.pk {
+		if k == i {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *metadata) insertFields(v reflect.Value, upsert bool) []writeField {
+	var fields []writeField
+	for _, f := range m.fields {
+		generated := f.options.auto || m.auto == f.index
+		if f.options.readonly || generated && !(upsert && m.isKey(f.index) && v.IsValid() && !v.Field(f.index).IsZero()) {
+			continue
+		}
+
+		field := writeField{index: f.index, column: f.column}
+		if v.IsValid() && v.Field(f.index).IsZero() {
+			switch {
+			case f.options.defaultExpr != "":
+				field.expr = f.options.defaultExpr
+			case f.options.omitempty || f.options.hasDefault:
+				continue
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func (m *metadata) updateFields() []writeField {
+	var fields []writeField
+	for _, f := range m.fields {
+		if f.options.auto || f.options.readonly || m.isKey(f.index) || m.version == f.index || m.softDelete == f.index {
+			continue
+		}
+		fields = append(fields, writeField{index: f.index, column: f.column})
+	}
+
+	return fields
+}
+
+func (m *metadata) keyValues(v reflect.Value) []interface{} {
+	values := make([]interface{}, len(m.pk))
+	for i, k := range m.pk {
+		values[i] = v.Field(k).Interface()
+	}
+
+	return values
+}
+
+func keyString(values []interface{}) string {
+	return fmt.Sprintf("%#v", values)
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+func keyCondition(cols []string, values [][]interface{}) Condition {
+	if len(cols) == 1 {
+		ids := make([]interface{}, len(values))
+		for i, v := range values {
+			ids[i] = v[0]
+		}
+		if len(ids) == 1 {
+			return Eq(cols[0], ids[0])
+		}
+
+		return In(cols[0], ids...)
+	}
+
+	var rows []Condition
+	for _, v := range values {
+		var eqs []Condition
+		for i, col := range cols {
+			eqs = append(eqs, Eq(col, v[i]))
+		}
+		rows = append(rows, And(eqs...))
+	}
+	if len(rows) == 1 {
+		return rows[0]
+	}
+
+	return Or(rows...)
+}
+
+func addVersion(f reflect.Value, delta int64) {
+	switch f.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f.SetUint(uint64(int64(f.Uint()) + delta))
+	default:
+		f.SetInt(f.Int() + delta)
+	}
+}
+
+func setGeneratedKey(f reflect.Value, id int64) {
+	switch f.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f.SetUint(uint64(id))
+	default:
+		f.SetInt(id)
+	}
+}
@@ -0,0 +1,183 @@
+package goquery
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColumn(t *testing.T) {
+	type (
+		user struct {
+			Id        int64  `json:"id" column:"user_id, pk, AUTO"`
+			Email     string `json:"email" column:"email,omitempty"`
+			CreatedAt string `json:"created_at" column:"created_at,readonly,default=COALESCE(NULL, CURRENT_TIMESTAMP)"`
+			Status    string `json:"status" column:"status,default"`
+			Password  string `json:"-" column:"-"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+
+	col, options := parseColumn(reflectT.Field(0))
+	assert.Equal(t, "user_id", col)
+	assert.Equal(t, columnOptions{pk: true, auto: true}, options)
+	assert.Equal(t, "user_id", getColumn(reflectT.Field(0)))
+
+	col, options = parseColumn(reflectT.Field(1))
+	assert.Equal(t, "email", col)
+	assert.Equal(t, columnOptions{omitempty: true}, options)
+
+	_, options = parseColumn(reflectT.Field(2))
+	assert.Equal(t, columnOptions{readonly: true, hasDefault: true, defaultExpr: "COALESCE(NULL, CURRENT_TIMESTAMP)"}, options)
+
+	_, options = parseColumn(reflectT.Field(3))
+	assert.Equal(t, columnOptions{hasDefault: true}, options)
+
+	assert.Equal(t, "", getColumn(reflectT.Field(4)))
+}
+
+func TestWriteFields(t *testing.T) {
+	type (
+		user struct {
+			Id        int64  `column:"id"`
+			Email     string `column:"email,omitempty"`
+			Status    string `column:"status,default"`
+			CreatedAt string `column:"created_at,default=CURRENT_TIMESTAMP"`
+			UpdatedAt string `column:"updated_at,readonly"`
+			Version   int64  `column:"version,auto"`
+			Password  string `column:"-"`
+		}
+	)
+	m := getMetadata(reflect.TypeOf(user{}))
+
+	assert.Equal(t, 5, m.auto)
+	assert.False(t, m.generated)
+
+	assert.Equal(t, []writeField{{0, "id", ""}, {1, "email", ""}, {2, "status", ""}, {3, "created_at", ""}}, m.insertFields(reflect.Value{}, false))
+	assert.Equal(t, []writeField{{0, "id", ""}, {3, "created_at", "CURRENT_TIMESTAMP"}}, m.insertFields(reflect.ValueOf(user{Id: 1}), false))
+	assert.Equal(t, []writeField{{0, "id", ""}, {1, "email", ""}, {2, "status", ""}, {3, "created_at", ""}}, m.insertFields(reflect.ValueOf(user{1, "a@email.com", "active", "2016-01-01", "", 0, ""}), false))
+	assert.Equal(t, []writeField{{1, "email", ""}, {2, "status", ""}, {3, "created_at", ""}}, m.updateFields())
+}
+
+func TestPrimaryKey(t *testing.T) {
+	type (
+		user struct {
+			Email string `json:"email" column:"email"`
+			Id    int64  `json:"id" column:"id"`
+		}
+		account struct {
+			Name string `column:"name"`
+			Uuid string `column:"uuid,pk"`
+			Id   int64  `column:"id"`
+		}
+		membership struct {
+			UserId  uint   `column:"user_id,pk"`
+			GroupId uint   `column:"group_id,pk"`
+			Role    string `column:"role"`
+		}
+		log struct {
+			Message string `column:"message"`
+		}
+	)
+
+	m := getMetadata(reflect.TypeOf(user{}))
+	assert.Equal(t, []int{1}, m.pk)
+	assert.True(t, m.generated)
+
+	m = getMetadata(reflect.TypeOf(account{}))
+	assert.Equal(t, []int{1}, m.pk)
+	assert.False(t, m.generated)
+
+	m = getMetadata(reflect.TypeOf(membership{}))
+	assert.Equal(t, []int{0, 1}, m.pk)
+	assert.False(t, m.generated)
+	assert.Equal(t, []string{"user_id", "group_id"}, m.keys)
+	assert.Equal(t, []interface{}{uint(1), uint(2)}, m.keyValues(reflect.ValueOf(membership{1, 2, "admin"})))
+
+	assert.Empty(t, getMetadata(reflect.TypeOf(log{})).pk)
+}
+
+func TestMetadata(t *testing.T) {
+	type (
+		orderItem struct {
+			Id    int64                  `column:"id"`
+			Name  string                 `column:"name"`
+			Extra map[string]interface{} `column:"*"`
+		}
+	)
+	reflectT := reflect.TypeOf(orderItem{})
+
+	var wg sync.WaitGroup
+	results := make([]*metadata, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = getMetadata(reflectT)
+		}(i)
+	}
+	wg.Wait()
+
+	m := results[0]
+	for _, r := range results {
+		assert.True(t, m == r)
+	}
+	assert.Equal(t, "orderitems", m.table)
+	assert.Equal(t, []mappedField{{0, "id", columnOptions{}}, {1, "name", columnOptions{}}}, m.fields)
+	assert.Equal(t, map[string]int{"id": 0, "name": 1}, m.byName)
+	assert.Equal(t, 2, m.extra)
+
+	SetNamingStrategy(NamingStrategy{SnakeCase: true})
+	defer SetNamingStrategy(DefaultNamingStrategy)
+
+	assert.False(t, m == getMetadata(reflectT))
+	assert.Equal(t, "order_items", getMetadata(reflectT).table)
+}
+
+func TestVersionColumn(t *testing.T) {
+	type (
+		user struct {
+			Id      int64  `column:"id"`
+			Email   string `column:"email"`
+			Version uint32 `column:"version,version"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+
+	_, options := parseColumn(reflectT.Field(2))
+	assert.Equal(t, columnOptions{version: true}, options)
+
+	m := getMetadata(reflectT)
+	assert.Equal(t, 2, m.version)
+	assert.Equal(t, []writeField{{1, "email", ""}}, m.updateFields())
+
+	v := reflect.ValueOf(&user{Version: 1}).Elem()
+	addVersion(v.Field(2), 1)
+	assert.Equal(t, uint32(2), v.Field(2).Interface())
+
+	type (
+		stamped struct {
+			Id      int64  `column:"id"`
+			Version string `column:"version,version"`
+		}
+	)
+	m = getMetadata(reflect.TypeOf(stamped{}))
+	assert.Equal(t, -1, m.version)
+	assert.EqualError(t, m.err, "query: Unsupported version type string for stamped.Version!")
+}
+
+func TestKeyCondition(t *testing.T) {
+	sql, args := keyCondition([]string{"id"}, [][]interface{}{{1}}).ToSQL()
+	assert.Equal(t, "id = ?", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, args = keyCondition([]string{"id"}, [][]interface{}{{1}, {2}}).ToSQL()
+	assert.Equal(t, "id IN (?, ?)", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	sql, args = keyCondition([]string{"user_id", "group_id"}, [][]interface{}{{1, 2}, {3, 4}}).ToSQL()
+	assert.Equal(t, "(user_id = ? AND group_id = ?) OR (user_id = ? AND group_id = ?)", sql)
+	assert.Equal(t, []interface{}{1, 2, 3, 4}, args)
+}
@@ -0,0 +1,92 @@
+package goquery
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gedex/inflector"
+)
+
+type (
+	TableNamer interface {
+		TableName() string
+	}
+
+	NamingStrategy struct {
+		SnakeCase bool
+		Singular  bool
+		Prefix    string
+		Schema    string
+	}
+)
+
+var (
+	DefaultNamingStrategy = NamingStrategy{}
+
+	namingMu       sync.RWMutex
+	namingStrategy = DefaultNamingStrategy
+)
+
+func SetNamingStrategy(strategy NamingStrategy) {
+	namingMu.Lock()
+	defer namingMu.Unlock()
+
+	namingStrategy = strategy
+	resetMetadata()
+}
+
+func GetNamingStrategy() NamingStrategy {
+	namingMu.RLock()
+	defer namingMu.RUnlock()
+
+	return namingStrategy
+}
+
+func (n NamingStrategy) TableName(name string) string {
+	if n.SnakeCase {
+		name = snakeCase(name)
+	} else {
+		name = strings.ToLower(name)
+	}
+	if !n.Singular {
+		name = inflector.Pluralize(name)
+	}
+	name = n.Prefix + name
+	if n.Schema != "" {
+		name = n.Schema + "." + name
+	}
+
+	return name
+}
+
+func snakeCase(name string) string {
+	var (
+		buf   strings.Builder
+		runes = []rune(name)
+	)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				buf.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		buf.WriteRune(r)
+	}
+
+	return buf.String()
+}
+
+func getTable(t reflect.Type) string {
+	return getMetadata(t).table
+}
+
+func tableName(t reflect.Type) string {
+	if namer, ok := reflect.New(t).Interface().(TableNamer); ok {
+		return namer.TableName()
+	}
+
+	return GetNamingStrategy().TableName(t.Name())
+}
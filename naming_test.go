@@ -0,0 +1,51 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type legacyUser struct {
+	Id    int64  `json:"id" column:"id"`
+	Email string `json:"email" column:"email"`
+}
+
+func (legacyUser) TableName() string {
+	return "tbl_user"
+}
+
+func TestNamingStrategy(t *testing.T) {
+	assert.Equal(t, "orderitems", DefaultNamingStrategy.TableName("OrderItem"))
+	assert.Equal(t, "order_items", NamingStrategy{SnakeCase: true}.TableName("OrderItem"))
+	assert.Equal(t, "order_item", NamingStrategy{SnakeCase: true, Singular: true}.TableName("OrderItem"))
+	assert.Equal(t, "shop.app_people", NamingStrategy{Prefix: "app_", Schema: "shop"}.TableName("Person"))
+	assert.Equal(t, "http_request_log2s", NamingStrategy{SnakeCase: true}.TableName("HTTPRequestLog2"))
+}
+
+func TestTableNamer(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+
+	assert.Equal(t, "tbl_user", getTable(reflect.TypeOf(legacyUser{})))
+
+	q := New(reflect.TypeOf(legacyUser{})).SetDialect(MySQL).Delete(legacyUser{Id: 1}).GetQuery()
+	assert.Equal(t, "DELETE FROM `tbl_user` WHERE id = ?", q.GetSQL())
+
+	SetNamingStrategy(NamingStrategy{SnakeCase: true, Singular: true, Schema: "app"})
+	defer SetNamingStrategy(DefaultNamingStrategy)
+
+	assert.Equal(t, NamingStrategy{SnakeCase: true, Singular: true, Schema: "app"}, GetNamingStrategy())
+	assert.Equal(t, "tbl_user", getTable(reflect.TypeOf(legacyUser{})))
+
+	qBuilder := New(reflect.TypeOf(user{})).SetDialect(PostgreSQL)
+	assert.Equal(t, `SELECT "id" FROM "app"."user"`, qBuilder.Select("id").GetQuery().GetSQL())
+	insert := compileInsert(qBuilder.(*builder), &sqlQuery{}, getMetadata(qBuilder.(*builder).t).insertFields(reflect.Value{}, false), 1, nil)
+	assert.Equal(t, `INSERT INTO "app"."user" ("email") VALUES (?)`, insert.head+insert.values)
+	assert.Equal(t, `DELETE FROM "app"."user" WHERE id = $1`, qBuilder.Delete(user{Id: 1}).GetQuery().GetSQL())
+}
@@ -1,407 +1,840 @@
 package goquery
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
-
-	"github.com/gedex/inflector"
 )
 
 type (
 	Query interface {
-		GetResults(*sql.DB) (interface{}, error)
-		GetResult(*sql.DB) (interface{}, error)
-		GetCount(*sql.DB) (int64, error)
-		Execute(*sql.DB) (interface{}, error)
+		GetResults(Executor) (interface{}, error)
+		GetResult(Executor) (interface{}, error)
+		GetCount(Executor) (int64, error)
+		Execute(Executor) (interface{}, error)
+		GetResultsContext(context.Context, Executor) (interface{}, error)
+		GetResultContext(context.Context, Executor) (interface{}, error)
+		GetCountContext(context.Context, Executor) (int64, error)
+		ExecuteContext(context.Context, Executor) (interface{}, error)
 		GetSQL() string
+		GetParameters() []interface{}
+	}
+
+	Executor interface {
+		PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+		QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	}
+
+	txBeginner interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 	}
 
 	query struct {
 		builder *builder
 	}
+
+	ConflictError struct {
+		Type reflect.Type
+		Key  []interface{}
+	}
 )
 
-func (q *query) GetResults(db *sql.DB) (interface{}, error) {
-	slice := reflect.New(reflect.SliceOf(q.builder.t)).Elem()
-	ptr := reflect.New(q.builder.t)
-	entity := ptr.Elem()
-	fieldInfo, queryStr := prepareSelect(entity, q.builder)
+func (q *query) GetResults(db Executor) (interface{}, error) {
+	return q.GetResultsContext(context.Background(), db)
+}
 
-	stmt, err := db.Prepare(queryStr)
-	if err != nil {
-		return slice.Interface(), err
-	}
-	defer stmt.Close()
+func (q *query) GetResult(db Executor) (interface{}, error) {
+	return q.GetResultContext(context.Background(), db)
+}
 
-	rows, err := stmt.Query(q.builder.parameters...)
-	if err != nil {
-		return slice.Interface(), err
-	}
-	defer rows.Close()
+func (q *query) GetCount(db Executor) (int64, error) {
+	return q.GetCountContext(context.Background(), db)
+}
 
-	for rows.Next() {
-		if err = rows.Scan(fieldInfo...); err != nil {
-			return slice.Interface(), err
-		}
-		slice.Set(reflect.Append(slice, entity))
-	}
+func (q *query) Execute(db Executor) (interface{}, error) {
+	return q.ExecuteContext(context.Background(), db)
+}
 
-	if err = rows.Err(); err != nil {
-		return slice.Interface(), err
-	}
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("query: %s %v was modified concurrently!", e.Type.Name(), e.Key)
+}
+
+func (q *query) GetResultsContext(ctx context.Context, db Executor) (interface{}, error) {
+	slice := reflect.New(reflect.SliceOf(q.builder.t)).Elem()
+
+	err := q.each(ctx, db, func(entity reflect.Value) bool {
+		slice.Set(reflect.Append(slice, entity))
+		return true
+	})
 
-	return slice.Interface(), nil
+	return slice.Interface(), err
 }
 
-func (q *query) GetResult(db *sql.DB) (interface{}, error) {
-	ptr := reflect.New(q.builder.t)
-	entity := ptr.Elem()
-	fieldInfo, queryStr := prepareSelect(entity, q.builder)
-	stmt, err := db.Prepare(queryStr)
-	if err != nil {
-		return nil, err
-	}
-	defer stmt.Close()
+func (q *query) GetResultContext(ctx context.Context, db Executor) (interface{}, error) {
+	var result interface{}
 
-	if err := stmt.QueryRow(q.builder.parameters...).Scan(fieldInfo...); err != nil {
-		return nil, err
+	err := q.each(ctx, db, func(entity reflect.Value) bool {
+		result = entity.Interface()
+		return false
+	})
+	if err == nil && result == nil {
+		err = sql.ErrNoRows
 	}
 
-	return entity.Interface(), nil
+	return result, err
 }
 
-func (q *query) GetCount(db *sql.DB) (int64, error) {
+func (q *query) GetCountContext(ctx context.Context, db Executor) (int64, error) {
 	var count int64
 
-	stmt, err := db.Prepare(q.GetSQL())
+	queryStr, args, err := q.build()
+	if err != nil {
+		return count, err
+	}
+
+	stmt, err := db.PrepareContext(ctx, queryStr)
 	if err != nil {
 		return count, err
 	}
 	defer stmt.Close()
 
-	if err := stmt.QueryRow(q.builder.parameters...).Scan(count); err != nil {
+	if err := stmt.QueryRowContext(ctx, args...).Scan(&count); err != nil {
 		return count, err
 	}
 
 	return count, nil
 }
 
-func (q *query) Execute(db *sql.DB) (interface{}, error) {
+func (q *query) ExecuteContext(ctx context.Context, db Executor) (interface{}, error) {
 	switch q.builder.statement {
 	case "save":
-		return save(q, db)
+		return save(ctx, q, db)
+	case "update", "restore":
+		return update(ctx, q, db)
 	case "delete":
-		return remove(q, db)
+		return remove(ctx, q, db)
 	default:
 		return nil, errors.New("query: Invalid execute statement!")
 	}
 }
 
+func (q *query) each(ctx context.Context, db Executor, fn func(entity reflect.Value) bool) error {
+	queryStr, args, err := q.build()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := db.PrepareContext(ctx, queryStr)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	s, err := newScanner(q.builder, columns)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		entity := reflect.New(q.builder.t).Elem()
+		if err = s.scan(rows, entity); err != nil {
+			return err
+		}
+		if q.builder.tracker != nil {
+			q.builder.tracker.snapshot(getMetadata(q.builder.t), entity)
+		}
+		if !fn(entity) {
+			break
+		}
+	}
+
+	return rows.Err()
+}
+
 func (q *query) GetSQL() string {
+	queryStr, _, _ := q.build()
+
+	return queryStr
+}
+
+func (q *query) GetParameters() []interface{} {
+	_, args, _ := q.build()
+
+	return args
+}
+
+func (q *query) build() (string, []interface{}, error) {
+	if q.builder.err != nil {
+		return "", nil, q.builder.err
+	}
+	if q.builder.statement == "update" && len(q.builder.sets) == 0 {
+		return "", nil, errors.New("query: No columns to update!")
+	}
+	if b := q.builder; b.statement != "select" && b.statement != "count" && (len(compileOrder(b)) > 0 || b.limit > 0 || b.offset > 0) {
+		if b.offset > 0 || !b.dialect.WriteLimit() {
+			return "", nil, fmt.Errorf("query: ORDER BY, LIMIT and OFFSET are not supported in %s by %T!", strings.ToUpper(b.statement), b.dialect)
+		}
+	}
+
+	return compile(q.builder, q.builder.statement).render(q.builder.dialect, q.builder.parameters)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func getField(f reflect.Value) interface{} {
+	return f.Addr().Interface()
+}
+
+func getColumn(f reflect.StructField) string {
+	if col, _ := parseColumn(f); col != "*" && col != "-" {
+		return col
+	}
+
+	return ""
+}
+
+func selectColumns(t reflect.Type, columns []string, qualifier string) ([]string, []int) {
 	var (
-		cols      = make(map[string]string)
-		colsCount int
-		queryStr  string
+		exprs  []string
+		fields []int
+		names  = make(map[string]int)
 	)
+	for _, f := range getMetadata(t).fields {
+		col := f.column
+		names[col] = f.index
+		if len(columns) == 0 {
+			if qualifier != "" {
+				col = qualifier + "." + col
+			}
+			exprs = append(exprs, col)
+			fields = append(fields, f.index)
+		}
+	}
 
-	table := getTable(q.builder.t)
+	for _, col := range columns {
+		name := col
+		if i := strings.LastIndex(strings.ToUpper(col), " AS "); i >= 0 {
+			name = strings.TrimSpace(col[i+4:])
+		} else if i := strings.LastIndex(col, "."); i >= 0 && isIdentifier(col) {
+			name = col[i+1:]
+		} else if qualifier != "" && isIdentifier(col) {
+			col = qualifier + "." + col
+		}
 
-	switch q.builder.statement {
-	case "select":
-		for _, col := range q.builder.columns {
-			cols[col] = col
-			colsCount++
-		}
-		for i := 0; i < q.builder.t.NumField(); i++ {
-			col := getColumn(q.builder.t.Field(i))
-			if _, ok := cols[col]; col != "" && (ok || colsCount == 0) {
-				if queryStr == "" {
-					queryStr += "SELECT "
-					if q.builder.distinct {
-						queryStr += "DISTINCT "
-					}
-					queryStr += col
-				} else {
-					queryStr += ", " + col
+		exprs = append(exprs, col)
+		if i, ok := names[name]; ok {
+			fields = append(fields, i)
+		}
+	}
+
+	return exprs, fields
+}
+
+func save(ctx context.Context, q *query, db Executor) (interface{}, error) {
+	var (
+		b = q.builder
+		m = getMetadata(b.t)
+	)
+	if b.err != nil {
+		return nil, b.err
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	result, entities, err := saveEntities(b.v, b.t)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.pk) == 0 && (b.upsert == nil || len(b.upsert.target) == 0) {
+		return nil, fmt.Errorf("query: No primary key for %s!", b.t.Name())
+	}
+	if len(b.returning) > 0 && !supportsReturning(b.dialect) {
+		return nil, errors.New("query: Dialect does not support RETURNING!")
+	}
+	if len(b.returning) > 0 && b.upsert != nil && b.upsert.nothing {
+		return nil, errors.New("query: RETURNING can not be combined with DoNothing!")
+	}
+
+	tx, owned, err := beginTx(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if owned != nil {
+		defer owned.Rollback()
+	}
+
+	stmts := make(map[string]*sql.Stmt)
+	defer func() {
+		for _, stmt := range stmts {
+			stmt.Close()
+		}
+	}()
+
+	var (
+		update   = compile(b, "save")
+		stmtU    *sql.Stmt
+		existing map[string]bool
+	)
+	if b.upsert == nil {
+		if stmtU, err = prepare(ctx, tx, stmts, update, b.dialect); err != nil {
+			return nil, err
+		}
+	}
+	if b.upsert == nil && !m.generated {
+		var unknown []reflect.Value
+		for _, s := range entities {
+			if b.tracker != nil {
+				if _, tracked := b.tracker.changes(m, s, nil); tracked {
+					continue
 				}
 			}
+			unknown = append(unknown, s)
 		}
-		break
-	case "count":
-		queryStr = "SELECT COUNT("
-		if q.builder.distinct {
-			queryStr += "DISTINCT "
-		}
-		queryStr += q.builder.column + ")"
-		break
-	case "insert":
-		var values string
-		for i := 1; i < q.builder.t.NumField(); i++ {
-			col := getColumn(q.builder.t.Field(i))
-			if _, ok := cols[col]; col != "" && (ok || colsCount == 0) {
-				if values == "" {
-					queryStr += "INSERT INTO " + table + " (" + col
-					values = "?"
-				} else {
-					queryStr += ", " + col
-					values += ", ?"
-				}
+		if existing, err = existingKeys(ctx, tx, b, unknown); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		pending       []reflect.Value
+		pendingFields []writeField
+		versioned     []reflect.Value
+		generated     []reflect.Value
+		saved         bool
+	)
+	defer func() {
+		if !saved {
+			for _, s := range versioned {
+				addVersion(s.Field(m.version), -1)
+			}
+			for _, s := range generated {
+				s.Field(m.auto).Set(reflect.Zero(s.Field(m.auto).Type()))
 			}
 		}
-		queryStr += ") VALUES (" + values + ")"
-		break
-	case "update":
-		for i := 1; i < q.builder.t.NumField(); i++ {
-			col := getColumn(q.builder.t.Field(i))
-			if _, ok := cols[col]; col != "" && (ok || colsCount == 0) {
-				if queryStr == "" {
-					queryStr += "UPDATE  " + table + " SET " + col + "=?"
-				} else {
-					queryStr += ", " + col + "=?"
+	}()
+	for _, s := range entities {
+		if err := ctx.Err(); err != nil {
+			return result.Interface(), err
+		}
+
+		var (
+			isNew   = b.upsert != nil
+			fields  = m.updateFields()
+			tracked bool
+		)
+		if !isNew && b.tracker != nil {
+			fields, tracked = b.tracker.changes(m, s, fields)
+		}
+		if !isNew && !tracked {
+			isNew = isNewEntity(s, m, existing)
+		}
+
+		if !isNew {
+			if tracked && len(fields) == 0 {
+				continue
+			}
+			if err := insertRows(ctx, tx, stmts, b, pendingFields, pending); err != nil {
+				return result.Interface(), err
+			}
+			pending = pending[:0]
+
+			query, stmt := update, stmtU
+			if tracked {
+				query = compileSave(b, &sqlQuery{}, fields)
+				if stmt, err = prepare(ctx, tx, stmts, query, b.dialect); err != nil {
+					return result.Interface(), err
 				}
 			}
+
+			var values []interface{}
+			for _, f := range fields {
+				values = append(values, s.Field(f.index).Interface())
+			}
+			var version interface{}
+			if m.version >= 0 {
+				version = s.Field(m.version).Interface()
+				addVersion(s.Field(m.version), 1)
+				versioned = append(versioned, s)
+				values = append(values, s.Field(m.version).Interface())
+			}
+			values = append(values, m.keyValues(s)...)
+			if m.version >= 0 {
+				values = append(values, version)
+			}
+			_, args, err := query.render(b.dialect, values)
+			if err != nil {
+				return result.Interface(), err
+			}
+
+			var affected int64
+			if len(b.returning) > 0 {
+				affected, err = scanReturning(ctx, stmt, args, []reflect.Value{s}, m, false)
+			} else {
+				affected, err = execAffected(ctx, stmt, args, m.version >= 0)
+			}
+			if err != nil {
+				return result.Interface(), err
+			}
+			if m.version >= 0 && affected == 0 {
+				return result.Interface(), &ConflictError{b.t, m.keyValues(s)}
+			}
+			continue
 		}
-		queryStr += " WHERE id=?"
-		if q.builder.where != "" {
-			queryStr += " AND "
+
+		fields = m.insertFields(s, b.upsert != nil)
+		if len(pending) > 0 && !sameFields(fields, pendingFields) {
+			if err := insertRows(ctx, tx, stmts, b, pendingFields, pending); err != nil {
+				return result.Interface(), err
+			}
+			pending = pending[:0]
 		}
-		break
-	case "delete":
-		queryStr = "DELETE FROM " + table
-		break
+		if m.auto >= 0 && s.Field(m.auto).IsZero() {
+			generated = append(generated, s)
+		}
+		pending = append(pending, s)
+		pendingFields = fields
 	}
 
-	return finishSQL(q.builder, queryStr, table)
-}
+	if len(pending) > 0 {
+		if err := insertRows(ctx, tx, stmts, b, pendingFields, pending); err != nil {
+			return result.Interface(), err
+		}
+	}
 
-func finishSQL(builder *builder, queryStr, table string) string {
-	var from string
-	switch builder.statement {
-	case "select", "count":
-		if builder.from != "" {
-			from = builder.from
-		} else {
-			from = table
+	if owned != nil {
+		if err = owned.Commit(); err != nil {
+			return result.Interface(), err
 		}
-		queryStr += " FROM " + from
 	}
+	saved = true
+	if b.tracker != nil {
+		for _, s := range entities {
+			b.tracker.snapshot(m, s)
+		}
+	}
+
+	return result.Interface(), nil
+}
 
-	setWhere := false
-	if builder.where != "" {
-		setWhere = true
-		queryStr += " WHERE " + builder.where
+func saveEntities(v interface{}, t reflect.Type) (reflect.Value, []reflect.Value, error) {
+	result := reflect.ValueOf(v)
+	for result.Kind() == reflect.Ptr && !result.IsNil() {
+		result = result.Elem()
 	}
-	for i := 0; i < len(builder.andWhere); i++ {
-		if i == 0 && !setWhere {
-			setWhere = true
-			queryStr += " WHERE " + builder.andWhere[i]
-		} else {
-			queryStr += " AND " + builder.andWhere[i]
+
+	switch {
+	case result.Kind() == reflect.Struct && result.Type() == t:
+		if !result.CanAddr() {
+			entity := reflect.New(t).Elem()
+			entity.Set(result)
+			result = entity
 		}
+
+		return result, []reflect.Value{result}, nil
+	case result.Kind() == reflect.Slice && (result.Type().Elem() == t || result.Type().Elem() == reflect.PtrTo(t)):
+		entities := make([]reflect.Value, result.Len())
+		for i := range entities {
+			entity := result.Index(i)
+			if entity.Kind() == reflect.Ptr && entity.IsNil() {
+				return result, nil, fmt.Errorf("query: Nil %s at index %d!", t.Name(), i)
+			}
+			entities[i] = reflect.Indirect(entity)
+		}
+
+		return result, entities, nil
 	}
-	for i := 0; i < len(builder.orWhere); i++ {
-		if i == 0 && !setWhere {
-			setWhere = true
-			queryStr += " WHERE " + builder.orWhere[i]
-		} else {
-			queryStr += " OR " + builder.orWhere[i]
+
+	return result, nil, fmt.Errorf("query: Can not save %T, expected %s, []%s or []*%s!", v, t.Name(), t.Name(), t.Name())
+}
+
+func insertRows(ctx context.Context, db Executor, stmts map[string]*sql.Stmt, b *builder, fields []writeField, rows []reflect.Value) error {
+	var (
+		d         = b.dialect
+		m         = getMetadata(b.t)
+		auto      = m.auto
+		size      = len(rows)
+		params    int
+		returning = b.returning
+	)
+	for _, f := range fields {
+		if f.expr == "" {
+			params++
 		}
 	}
+	if params > 0 && size > d.MaxParameters()/params {
+		size = d.MaxParameters() / params
+	}
 
-	orderby := ""
-	for col, order := range builder.order {
-		if orderby == "" {
-			orderby += " ORDER BY " + col + " " + order
-		} else {
-			orderby += ", " + col + " " + order
+	switch {
+	case auto < 0:
+	case b.upsert != nil && b.upsert.nothing:
+		auto = -1
+	case b.upsert == nil && d.InsertIdRange() != InsertIdUnknown && len(returning) == 0:
+	case supportsReturning(d):
+		if col := m.field(auto).column; !containsColumn(returning, col) {
+			returning = append(returning[:len(returning):len(returning)], col)
+		}
+	case b.upsert != nil:
+		auto = -1
+	default:
+		size = 1
+	}
+	keyed := len(returning) > 0 && suppliedKeys(m, fields, rows)
+	if keyed {
+		for _, col := range m.keys {
+			if !containsColumn(returning, col) {
+				returning = append(returning[:len(returning):len(returning)], col)
+			}
 		}
+	} else if len(returning) > 0 {
+		size = 1
 	}
-	queryStr += orderby
+	if size < 1 {
+		size = 1
+	}
+
+	for len(rows) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	groupby := ""
-	for _, col := range builder.groupby {
-		if groupby == "" {
-			groupby += " GROUP BY " + col
-		} else {
-			groupby += ", " + col
+		n := size
+		if n > len(rows) {
+			n = len(rows)
+		}
+		chunk := rows[:n]
+		rows = rows[n:]
+
+		var values []interface{}
+		for _, s := range chunk {
+			for _, f := range fields {
+				if f.expr == "" {
+					values = append(values, s.Field(f.index).Interface())
+				}
+			}
+		}
+
+		insert := compileInsert(b, &sqlQuery{}, fields, n, returning)
+		stmt, err := prepare(ctx, db, stmts, insert, d)
+		if err != nil {
+			return err
+		}
+		_, args, err := insert.render(d, values)
+		if err != nil {
+			return err
+		}
+
+		if len(returning) > 0 {
+			if _, err := scanReturning(ctx, stmt, args, chunk, m, keyed); err != nil {
+				return err
+			}
+			continue
+		}
+
+		res, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return err
+		}
+		if auto < 0 {
+			continue
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if d.InsertIdRange() == InsertIdLast {
+			id -= int64(n - 1)
+		}
+		for i, s := range chunk {
+			setGeneratedKey(s.Field(auto), id+int64(i))
 		}
 	}
-	queryStr += groupby
 
-	setHaving := false
-	if builder.having != "" {
-		setHaving = true
-		queryStr += " HAVING " + builder.having
+	return nil
+}
+
+func scanReturning(ctx context.Context, stmt *sql.Stmt, args []interface{}, chunk []reflect.Value, m *metadata, keyed bool) (int64, error) {
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return 0, err
 	}
-	for i := 0; i < len(builder.andHaving); i++ {
-		if i == 0 && !setHaving {
-			setHaving = true
-			queryStr += " HAVING " + builder.andHaving[i]
-		} else {
-			queryStr += " AND " + builder.andHaving[i]
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	var byKey map[string]reflect.Value
+	if keyed {
+		byKey = make(map[string]reflect.Value, len(chunk))
+		for _, s := range chunk {
+			byKey[keyString(m.keyValues(s))] = s
 		}
 	}
-	for i := 0; i < len(builder.orHaving); i++ {
-		if i == 0 && !setHaving {
-			setHaving = true
-			queryStr += " HAVING " + builder.orHaving[i]
-		} else {
-			queryStr += " OR " + builder.orHaving[i]
+
+	var i int
+	for ; rows.Next(); i++ {
+		if i >= len(chunk) {
+			return int64(i), errors.New("query: Too many rows returned!")
+		}
+
+		entity := chunk[i]
+		if keyed {
+			entity = reflect.New(chunk[i].Type()).Elem()
+		}
+
+		dest := make([]interface{}, len(columns))
+		for j, col := range columns {
+			if f, ok := m.byName[strings.ToLower(col)]; ok {
+				dest[j] = getField(entity.Field(f))
+			} else {
+				dest[j] = new(interface{})
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return int64(i), err
+		}
+		if !keyed {
+			continue
+		}
+
+		s, ok := byKey[keyString(m.keyValues(entity))]
+		if !ok {
+			return int64(i), errors.New("query: Returned row does not match any saved entity!")
+		}
+		for _, col := range columns {
+			if f, ok := m.byName[strings.ToLower(col)]; ok {
+				s.Field(f).Set(entity.Field(f))
+			}
 		}
 	}
 
-	if builder.limit > 0 {
-		queryStr += " LIMIT " + strconv.FormatInt(builder.limit, 10)
+	return int64(i), rows.Err()
+}
+
+func suppliedKeys(m *metadata, fields []writeField, rows []reflect.Value) bool {
+	for _, k := range m.pk {
+		written := false
+		for _, f := range fields {
+			if f.index == k && f.expr == "" {
+				written = true
+			}
+		}
+		if !written {
+			return false
+		}
 	}
 
-	if builder.offset > 0 {
-		queryStr += " OFFSET " + strconv.FormatInt(builder.offset, 10)
+	seen := make(map[string]bool, len(rows))
+	for _, s := range rows {
+		key := keyString(m.keyValues(s))
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
 	}
 
-	return queryStr
+	return len(m.pk) > 0
 }
 
-func getTable(t reflect.Type) string {
-	return inflector.Pluralize(strings.ToLower(t.Name()))
-}
+func execAffected(ctx context.Context, stmt *sql.Stmt, args []interface{}, count bool) (int64, error) {
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil || !count {
+		return 0, err
+	}
 
-func getField(f reflect.Value) interface{} {
-	return f.Addr().Interface()
+	return res.RowsAffected()
 }
 
-func getColumn(f reflect.StructField) string {
-	return f.Tag.Get("column")
+func supportsReturning(d Dialect) bool {
+	output, returning := d.Returning([]string{"*"}, false)
+
+	return output != "" || returning != ""
 }
 
-func getColumns(t reflect.Type) []string {
-	cols := []string{}
-	for i := 0; i < t.NumField(); i++ {
-		col := getColumn(t.Field(i))
-		if col != "" {
-			cols = append(cols, col)
+func sameFields(a, b []writeField) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
 		}
 	}
 
-	return cols
+	return true
 }
 
-func getFields(s reflect.Value) []interface{} {
-	fields := []interface{}{}
-	t := s.Type()
-	for i := 0; i < s.NumField(); i++ {
-		col := getColumn(t.Field(i))
-		if col != "" {
-			fields = append(fields, getField(s.Field(i)))
-		}
+func prepare(ctx context.Context, db Executor, stmts map[string]*sql.Stmt, s *sqlQuery, d Dialect) (*sql.Stmt, error) {
+	queryStr, _, err := s.render(d, nil)
+	if err != nil {
+		return nil, err
+	}
+	if stmt, ok := stmts[queryStr]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := db.PrepareContext(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	stmts[queryStr] = stmt
+
+	return stmt, nil
+}
+
+func isNewEntity(s reflect.Value, m *metadata, existing map[string]bool) bool {
+	if existing == nil {
+		return s.Field(m.pk[0]).IsZero()
 	}
 
-	return fields
+	return !existing[keyString(m.keyValues(s))]
 }
 
-func prepareSelect(s reflect.Value, builder *builder) ([]interface{}, string) {
+func existingKeys(ctx context.Context, db Executor, b *builder, entities []reflect.Value) (map[string]bool, error) {
 	var (
-		cols      = make(map[string]string)
-		fieldInfo []interface{}
-		queryStr  string
-		colsCount int
+		d        = b.dialect
+		m        = getMetadata(b.t)
+		size     = d.MaxParameters() / len(m.keys)
+		existing = make(map[string]bool)
+		cols     = make([]string, len(m.keys))
 	)
+	for i, col := range m.keys {
+		cols[i] = d.Quote(col)
+	}
+	if size < 1 {
+		size = 1
+	}
 
-	for _, col := range builder.columns {
-		cols[col] = col
-		colsCount++
+	for len(entities) > 0 {
+		n := size
+		if n > len(entities) {
+			n = len(entities)
+		}
+
+		values := make([][]interface{}, n)
+		for i, s := range entities[:n] {
+			values[i] = m.keyValues(s)
+		}
+		entities = entities[n:]
+
+		exists := &sqlQuery{head: "SELECT " + strings.Join(cols, ", "), from: d.Quote(getTable(b.t)), where: []clause{{"", keyCondition(cols, values)}}}
+		queryStr, args, err := exists.render(d, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := scanKeys(ctx, db, m, b.t, queryStr, args, existing); err != nil {
+			return nil, err
+		}
 	}
 
-	t := s.Type()
-	for i := 0; i < s.NumField(); i++ {
-		col := getColumn(t.Field(i))
-		if _, ok := cols[col]; col != "" && (ok || colsCount == 0) {
-			fieldInfo = append(fieldInfo, getField(s.Field(i)))
-			if queryStr == "" {
-				queryStr += "SELECT "
-				if builder.distinct {
-					queryStr += "DISTINCT "
-				}
-				queryStr += col
-			} else {
-				queryStr += ", " + col
-			}
+	return existing, nil
+}
+
+func scanKeys(ctx context.Context, db Executor, m *metadata, t reflect.Type, queryStr string, args []interface{}, existing map[string]bool) error {
+	rows, err := db.QueryContext(ctx, queryStr, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dest := make([]interface{}, len(m.pk))
+		for i, k := range m.pk {
+			dest[i] = reflect.New(t.Field(k).Type).Interface()
 		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		key := make([]interface{}, len(dest))
+		for i, v := range dest {
+			key[i] = reflect.ValueOf(v).Elem().Interface()
+		}
+		existing[keyString(key)] = true
 	}
 
-	return fieldInfo, finishSQL(builder, queryStr, getTable(t))
+	return rows.Err()
 }
 
-func save(q *query, db *sql.DB) (interface{}, error) {
-	slice := reflect.ValueOf(q.builder.v)
-	if slice.Kind() == reflect.Ptr {
-		slice = slice.Elem()
+func beginTx(ctx context.Context, db Executor) (Executor, *sql.Tx, error) {
+	beginner, ok := db.(txBeginner)
+	if !ok {
+		return db, nil, nil
 	}
 
-	tx, err := db.Begin()
+	tx, err := beginner.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer tx.Rollback()
 
-	q.builder.statement = "update"
-	stmtU, err := tx.Prepare(q.GetSQL())
+	return tx, tx, nil
+}
+
+func remove(ctx context.Context, q *query, db Executor) (interface{}, error) {
+	if len(q.builder.returning) > 0 {
+		if !supportsReturning(q.builder.dialect) {
+			return nil, errors.New("query: Dialect does not support RETURNING!")
+		}
+
+		return q.GetResultsContext(ctx, db)
+	}
+
+	queryStr, args, err := q.build()
 	if err != nil {
 		return nil, err
 	}
-	defer stmtU.Close()
 
-	q.builder.statement = "insert"
-	stmtA, err := tx.Prepare(q.GetSQL())
+	res, err := db.ExecContext(ctx, queryStr, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer stmtA.Close()
-
-	for i := 0; i < slice.Len(); i++ {
-		var fieldInfo []interface{}
-		s := slice.Index(i)
-		for j := 1; j < s.NumField(); j++ {
-			fieldInfo = append(fieldInfo, s.Field(j).Interface())
-		}
-		isNew := s.Field(0).Int() == int64(0)
-		if !isNew {
-			fieldInfo = append(fieldInfo, s.Field(0).Interface())
-		}
 
-		if isNew {
-			res, err := stmtA.Exec(fieldInfo...)
-			if err != nil {
-				return slice.Interface(), err
-			}
+	return res.RowsAffected()
+}
 
-			id, err := res.LastInsertId()
-			if err != nil {
-				return slice.Interface(), err
-			}
-			s.FieldByName("Id").SetInt(id)
-		} else {
-			_, err := stmtU.Exec(fieldInfo...)
-			if err != nil {
-				return slice.Interface(), err
-			}
+func update(ctx context.Context, q *query, db Executor) (interface{}, error) {
+	if len(q.builder.returning) > 0 {
+		if !supportsReturning(q.builder.dialect) {
+			return nil, errors.New("query: Dialect does not support RETURNING!")
 		}
-	}
 
-	if err = tx.Commit(); err != nil {
-		return slice.Interface(), err
+		return q.GetResultsContext(ctx, db)
 	}
 
-	return slice.Interface(), nil
-}
+	queryStr, args, err := q.build()
+	if err != nil {
+		return nil, err
+	}
 
-func remove(q *query, db *sql.DB) (interface{}, error) {
-	_, err := db.Exec(q.GetSQL(), q.builder.parameters...)
+	res, err := db.ExecContext(ctx, queryStr, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return nil, nil
+	return res.RowsAffected()
 }
@@ -1,11 +1,19 @@
 package goquery
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
-func ExampleParseQuery() {
+func Example_parseQuery() {
 	type (
 		user struct {
 			Id    int64  `json:"id" column:"id"`
@@ -32,10 +40,10 @@ func ExampleParseQuery() {
 		GetSQL()
 
 	fmt.Println(sql)
-	// Output: SELECT id, email FROM users WHERE id = ? AND col2 = ? OR col3 = ? ORDER BY id DESC GROUP BY id, email HAVING COUNT(col1) > ? AND COUNT(col2) > ? OR COUNT(col3) > ? LIMIT 10 OFFSET 5
+	// Output: SELECT id, col1, email, col2, col3 FROM users WHERE (id = ? AND col2 = ?) OR col3 = ? GROUP BY id, email HAVING (COUNT(col1) > ? AND COUNT(col2) > ?) OR COUNT(col3) > ? ORDER BY id DESC LIMIT 10 OFFSET 5
 }
 
-func ExampleParseEmptySelect() {
+func Example_parseEmptySelect() {
 	type (
 		user struct {
 			Id    int64  `json:"id" column:"id"`
@@ -54,7 +62,7 @@ func ExampleParseEmptySelect() {
 	// Output: SELECT id, email FROM users
 }
 
-func ExampleParseFrom() {
+func Example_parseFrom() {
 	type (
 		user struct {
 			Id    int64  `json:"id" column:"id"`
@@ -74,7 +82,7 @@ func ExampleParseFrom() {
 	// Output: SELECT id, email FROM foo
 }
 
-func ExampleParseCount() {
+func Example_parseCount() {
 	type (
 		user struct {
 			Id    int64  `json:"id" column:"id"`
@@ -93,7 +101,7 @@ func ExampleParseCount() {
 	// Output: SELECT COUNT(id) FROM users
 }
 
-func ExampleParseOrder() {
+func Example_parseOrder() {
 	type (
 		user struct {
 			Id    int64  `json:"id" column:"id"`
@@ -113,7 +121,7 @@ func ExampleParseOrder() {
 	// Output: SELECT id FROM users ORDER BY id DESC
 }
 
-func ExampleParseAddOrder() {
+func Example_parseAddOrder() {
 	type (
 		user struct {
 			Id    int64  `json:"id" column:"id"`
@@ -133,7 +141,7 @@ func ExampleParseAddOrder() {
 	// Output: SELECT id FROM users ORDER BY email ASC
 }
 
-func ExampleParseDistinct() {
+func Example_parseDistinct() {
 	type (
 		user struct {
 			Id    int64  `json:"id" column:"id"`
@@ -153,7 +161,7 @@ func ExampleParseDistinct() {
 	// Output: SELECT DISTINCT id FROM users
 }
 
-func ExampleParseDistinctCount() {
+func Example_parseDistinctCount() {
 	type (
 		user struct {
 			Id    int64  `json:"id" column:"id"`
@@ -173,7 +181,7 @@ func ExampleParseDistinctCount() {
 	// Output: SELECT COUNT(DISTINCT id) FROM users
 }
 
-func ExampleParseDelete() {
+func Example_parseDelete() {
 	type (
 		user struct {
 			Id    int64  `json:"id" column:"id"`
@@ -192,3 +200,729 @@ func ExampleParseDelete() {
 	fmt.Println(sql)
 	// Output: DELETE FROM users WHERE id = ?
 }
+
+func Example_parseWhereOrder() {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	sql := qBuilder.
+		Select().
+		Where("id = ?").
+		OrWhere("email = ?").
+		AndWhere("id > ? OR id < ?").
+		AndWhere(Or(Eq("email", "a"), And(IsNull("email"), NotEq("id", 0)))).
+		OrWhere("id = 1").
+		GetQuery().
+		GetSQL()
+
+	fmt.Println(sql)
+	// Output: SELECT id, email FROM users WHERE ((id = ? OR email = ?) AND (id > ? OR id < ?) AND (email = ? OR (email IS NULL AND id <> ?))) OR id = 1
+}
+
+func Example_parseMultipleOrder() {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	sql := qBuilder.
+		Select("id").
+		OrderBy("LOWER(email)", "ASC").
+		AddOrderBy("id", "DESC").
+		AddOrderBy("created_at", "").
+		GetQuery().
+		GetSQL()
+
+	fmt.Println(sql)
+	// Output: SELECT id FROM users ORDER BY LOWER(email) ASC, id DESC, created_at
+}
+
+func Example_parseJoin() {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+			Title string `json:"title" column:"title"`
+		}
+	)
+	reflectT := reflect.TypeOf(user{})
+	qBuilder := New(reflectT)
+
+	sql := qBuilder.
+		Select("u.id", "email", "p.title").
+		Alias("u").
+		LeftJoin("posts", "p", "p.user_id = u.id AND p.status = ?", "published").
+		CrossJoin("tags", "t").
+		Where("u.id > ?").
+		GetQuery().
+		GetSQL()
+
+	fmt.Println(sql)
+	// Output: SELECT u.id, u.email, p.title FROM users u LEFT JOIN posts p ON p.user_id = u.id AND p.status = ? CROSS JOIN tags t WHERE u.id > ?
+}
+
+func TestQueryContext(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	db := &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			if query == "SELECT COUNT(*) FROM users" {
+				return &testRows{[]string{"COUNT(*)"}, [][]driver.Value{{int64(2)}}}, nil
+			}
+			return &testRows{[]string{"id", "email"}, [][]driver.Value{{int64(1), "a@email.com"}, {int64(2), "b@email.com"}}}, nil
+		},
+	}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	ctx := context.Background()
+	qBuilder := New(reflect.TypeOf(user{}))
+
+	results, err := qBuilder.Select().GetQuery().GetResultsContext(ctx, conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []user{{1, "a@email.com"}, {2, "b@email.com"}}, results)
+
+	result, err := qBuilder.Select().Where("id = ?").SetParameters(1).GetQuery().GetResultContext(ctx, conn)
+	assert.NoError(t, err)
+	assert.Equal(t, user{1, "a@email.com"}, result)
+
+	count, err := qBuilder.Reset().Count("").GetQuery().GetCountContext(ctx, conn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, err = qBuilder.Delete().Where("id = ?").SetParameters(1).GetQuery().ExecuteContext(ctx, conn)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users WHERE id = ?", db.queries[len(db.queries)-1])
+	assert.Equal(t, []driver.Value{int64(1)}, db.args[len(db.args)-1])
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = qBuilder.Select().GetQuery().GetResultsContext(canceled, conn)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = qBuilder.Count("").GetQuery().GetCountContext(canceled, conn)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestSaveContextCancel(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	db := &testDB{}
+	db.result = func(query string, args []driver.Value) (driver.Result, error) {
+		cancel()
+		return testResult(1), nil
+	}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	users := []user{{Email: "a@email.com"}, {Email: "b@email.com"}}
+	_, err := New(reflect.TypeOf(user{})).Save(users).GetQuery().ExecuteContext(ctx, conn)
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, db.queries, 1)
+	assert.Equal(t, 0, db.commits)
+}
+
+func TestExecutor(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	ctx := context.Background()
+	db := &testDB{}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	var (
+		_ Executor = conn
+		_ Executor = &sql.Tx{}
+		_ Executor = &sql.Conn{}
+	)
+
+	qBuilder := New(reflect.TypeOf(user{}))
+
+	tx, err := conn.Begin()
+	assert.NoError(t, err)
+
+	_, err = qBuilder.Save([]user{{Email: "a@email.com"}}).GetQuery().Execute(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, db.commits)
+
+	_, err = qBuilder.Select().GetQuery().GetResults(tx)
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Rollback())
+	assert.Equal(t, 0, db.commits)
+	assert.Equal(t, 1, db.rollbacks)
+
+	c, err := conn.Conn(ctx)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	_, err = qBuilder.Save([]user{{Email: "b@email.com"}}).GetQuery().Execute(c)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, db.commits)
+
+	_, err = qBuilder.Count("").GetQuery().GetCount(c)
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+func TestRemoveSliceParameter(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	db := &testDB{}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	_, err := New(reflect.TypeOf(user{})).Delete().Where("id IN (?)").SetParameters([]int{1, 2}).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DELETE FROM users WHERE id IN (?, ?)"}, db.queries)
+	assert.Equal(t, [][]driver.Value{{int64(1), int64(2)}}, db.args)
+}
+
+func TestSavePrimaryKey(t *testing.T) {
+	type (
+		user struct {
+			Email string `json:"email" column:"email"`
+			Id    int32  `json:"id" column:"user_id,pk,auto"`
+		}
+		book struct {
+			ISBN  int64  `json:"isbn" column:"isbn,pk"`
+			Title string `json:"title" column:"title"`
+		}
+		membership struct {
+			Role    string `column:"role"`
+			UserId  string `column:"user_id,pk"`
+			GroupId string `column:"group_id,pk"`
+		}
+	)
+	db := &testDB{}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	users := []*user{{Email: "a@email.com"}, {"b@email.com", 7}}
+	_, err := New(reflect.TypeOf(user{})).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, &user{"a@email.com", 1}, users[0])
+	assert.Equal(t, []string{"INSERT INTO users (email) VALUES (?)", "UPDATE users SET email=? WHERE user_id=?"}, db.queries)
+	assert.Equal(t, [][]driver.Value{{"a@email.com"}, {"b@email.com", int64(7)}}, db.args)
+
+	db.queries, db.args = nil, nil
+	books := []book{{978, "Go"}}
+	_, err = New(reflect.TypeOf(book{})).Save(books).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SELECT isbn FROM books WHERE isbn = ?", "INSERT INTO books (isbn, title) VALUES (?, ?)"}, db.queries)
+	assert.Equal(t, [][]driver.Value{{int64(978)}, {int64(978), "Go"}}, db.args)
+
+	db = &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			return &testRows{[]string{"user_id", "group_id"}, [][]driver.Value{{"2", "b"}}}, nil
+		},
+	}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	memberships := []membership{{"admin", "1", "a"}, {"guest", "2", "b"}}
+	_, err = New(reflect.TypeOf(membership{})).Save(memberships).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"SELECT user_id, group_id FROM memberships WHERE (user_id = ? AND group_id = ?) OR (user_id = ? AND group_id = ?)",
+		"INSERT INTO memberships (role, user_id, group_id) VALUES (?, ?, ?)",
+		"UPDATE memberships SET role=? WHERE user_id=? AND group_id=?",
+	}, db.queries)
+	assert.Equal(t, [][]driver.Value{{"1", "a", "2", "b"}, {"admin", "1", "a"}, {"guest", "2", "b"}}, db.args)
+
+	db = &testDB{}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	memberships = []membership{{"a", "1", "a"}, {"b", "2", "b"}, {"c", "3", "c"}}
+	_, err = New(reflect.TypeOf(membership{})).SetDialect(smallDialect{Generic}).Save(memberships).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"SELECT user_id, group_id FROM memberships WHERE (user_id = ? AND group_id = ?) OR (user_id = ? AND group_id = ?)",
+		"SELECT user_id, group_id FROM memberships WHERE user_id = ? AND group_id = ?",
+		"INSERT INTO memberships (role, user_id, group_id) VALUES (?, ?, ?)",
+		"INSERT INTO memberships (role, user_id, group_id) VALUES (?, ?, ?)",
+		"INSERT INTO memberships (role, user_id, group_id) VALUES (?, ?, ?)",
+	}, db.queries)
+}
+
+func TestSaveColumnOptions(t *testing.T) {
+	type (
+		user struct {
+			Id        int64  `json:"id" column:"id,pk,auto"`
+			Email     string `json:"email" column:"email"`
+			Nickname  string `json:"nickname" column:"nickname,omitempty"`
+			CreatedAt string `json:"created_at" column:"created_at,default=CURRENT_TIMESTAMP"`
+			UpdatedAt string `json:"updated_at" column:"updated_at,readonly"`
+			Password  string `json:"-" column:"-"`
+		}
+	)
+	db := &testDB{}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	users := []user{{Email: "a@email.com"}, {Email: "b@email.com", Nickname: "b", CreatedAt: "2016-01-01"}, {Id: 9, Email: "c@email.com", Password: "secret"}}
+	_, err := New(reflect.TypeOf(user{})).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"INSERT INTO users (email, created_at) VALUES (?, CURRENT_TIMESTAMP)",
+		"INSERT INTO users (email, nickname, created_at) VALUES (?, ?, ?)",
+		"UPDATE users SET email=?, nickname=?, created_at=? WHERE id=?",
+	}, db.queries)
+	assert.Equal(t, [][]driver.Value{{"a@email.com"}, {"b@email.com", "b", "2016-01-01"}, {"c@email.com", "", "", int64(9)}}, db.args)
+	assert.Equal(t, int64(1), users[0].Id)
+	assert.Equal(t, int64(2), users[1].Id)
+
+	sql := New(reflect.TypeOf(user{})).Select().GetQuery().GetSQL()
+	assert.Equal(t, "SELECT id, email, nickname, created_at, updated_at FROM users", sql)
+}
+
+type smallDialect struct {
+	Dialect
+}
+
+func (smallDialect) MaxParameters() int {
+	return 5
+}
+
+func TestSaveBulkInsert(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+			Name  string `json:"name" column:"name"`
+		}
+	)
+	newUsers := func() []user {
+		return []user{{Email: "a@email.com"}, {Email: "b@email.com"}, {Email: "c@email.com"}, {Id: 9, Email: "d@email.com"}, {Email: "e@email.com"}}
+	}
+
+	db := &testDB{lastId: 10}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	users := newUsers()
+	_, err := New(reflect.TypeOf(user{})).SetDialect(smallDialect{MySQLConsecutiveIds}).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"INSERT INTO `users` (`email`, `name`) VALUES (?, ?), (?, ?)",
+		"INSERT INTO `users` (`email`, `name`) VALUES (?, ?)",
+		"UPDATE `users` SET `email`=?, `name`=? WHERE `id`=?",
+		"INSERT INTO `users` (`email`, `name`) VALUES (?, ?)",
+	}, db.queries)
+	assert.Equal(t, []driver.Value{"a@email.com", "", "b@email.com", ""}, db.args[0])
+	assert.Equal(t, []int64{11, 12, 9}, []int64{users[0].Id, users[1].Id, users[3].Id})
+
+	db = &testDB{lastId: 10}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	users = newUsers()[:3]
+	_, err = New(reflect.TypeOf(user{})).SetDialect(SQLite).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`INSERT INTO "users" ("email", "name") VALUES (?, ?), (?, ?), (?, ?)`}, db.queries)
+	assert.Equal(t, []int64{9, 10, 11}, []int64{users[0].Id, users[1].Id, users[2].Id})
+
+	id := int64(20)
+	db = &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			id++
+			return &testRows{[]string{"id"}, [][]driver.Value{{id}}}, nil
+		},
+	}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	users = newUsers()[:3]
+	_, err = New(reflect.TypeOf(user{})).SetDialect(PostgreSQL).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`INSERT INTO "users" ("email", "name") VALUES ($1, $2) RETURNING "id"`,
+		`INSERT INTO "users" ("email", "name") VALUES ($1, $2) RETURNING "id"`,
+		`INSERT INTO "users" ("email", "name") VALUES ($1, $2) RETURNING "id"`,
+	}, db.queries)
+	assert.Equal(t, []int64{21, 22, 23}, []int64{users[0].Id, users[1].Id, users[2].Id})
+
+	type (
+		book struct {
+			ISBN      int64  `json:"isbn" column:"isbn,pk"`
+			Title     string `json:"title" column:"title"`
+			CreatedAt string `json:"created_at" column:"created_at,readonly"`
+		}
+	)
+	db = &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			if strings.HasPrefix(query, "SELECT") {
+				return &testRows{[]string{"isbn"}, nil}, nil
+			}
+			return &testRows{[]string{"created_at", "isbn"}, [][]driver.Value{{"2016-01-02", int64(2)}, {"2016-01-01", int64(1)}}}, nil
+		},
+	}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	books := []book{{ISBN: 1, Title: "a"}, {ISBN: 2, Title: "b"}}
+	_, err = New(reflect.TypeOf(book{})).SetDialect(PostgreSQL).Save(books).Returning("created_at").GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "books" ("isbn", "title") VALUES ($1, $2), ($3, $4) RETURNING "created_at", "isbn"`, db.queries[1])
+	assert.Equal(t, []book{{1, "a", "2016-01-01"}, {2, "b", "2016-01-02"}}, books)
+
+	db = &testDB{}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	users = newUsers()[:2]
+	_, err = New(reflect.TypeOf(user{})).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"INSERT INTO users (email, name) VALUES (?, ?)", "INSERT INTO users (email, name) VALUES (?, ?)"}, db.queries)
+	assert.Equal(t, []int64{1, 2}, []int64{users[0].Id, users[1].Id})
+
+	db = &testDB{}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	users = newUsers()[:2]
+	_, err = New(reflect.TypeOf(user{})).SetDialect(MySQL).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"INSERT INTO `users` (`email`, `name`) VALUES (?, ?)", "INSERT INTO `users` (`email`, `name`) VALUES (?, ?)"}, db.queries)
+	assert.Equal(t, []int64{1, 2}, []int64{users[0].Id, users[1].Id})
+}
+
+func TestSaveUpsert(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+			Name  string `json:"name" column:"name"`
+		}
+	)
+
+	db := &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			if args[0] == "a@email.com" {
+				return &testRows{[]string{"id"}, [][]driver.Value{{int64(5)}}}, nil
+			}
+			return &testRows{[]string{"id"}, [][]driver.Value{{int64(3)}}}, nil
+		},
+	}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	users := []user{{Email: "a@email.com", Name: "a"}, {Id: 3, Email: "b@email.com", Name: "b"}}
+	_, err := New(reflect.TypeOf(user{})).SetDialect(PostgreSQL).Save(users).OnConflict("email").GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`INSERT INTO "users" ("email", "name") VALUES ($1, $2) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name" RETURNING "id"`,
+		`INSERT INTO "users" ("id", "email", "name") VALUES ($1, $2, $3) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name" RETURNING "id"`,
+	}, db.queries)
+	assert.Equal(t, int64(5), users[0].Id)
+
+	db = &testDB{}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	users = []user{{Email: "a@email.com", Name: "a"}, {Email: "b@email.com", Name: "b"}}
+	_, err = New(reflect.TypeOf(user{})).SetDialect(MySQL).Save(users).DoUpdate("name").GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"INSERT INTO `users` (`email`, `name`) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)"}, db.queries)
+	assert.Equal(t, [][]driver.Value{{"a@email.com", "a", "b@email.com", "b"}}, db.args)
+	assert.Equal(t, int64(0), users[0].Id)
+
+	db = &testDB{}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	_, err = New(reflect.TypeOf(user{})).SetDialect(SQLite).Save(users).OnConflict("email").DoNothing().GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`INSERT INTO "users" ("email", "name") VALUES (?, ?), (?, ?) ON CONFLICT ("email") DO NOTHING`}, db.queries)
+	assert.Equal(t, 0, db.rollbacks)
+}
+
+func TestSaveReturning(t *testing.T) {
+	type (
+		user struct {
+			Id        int64  `json:"id" column:"id"`
+			Email     string `json:"email" column:"email"`
+			CreatedAt string `json:"created_at" column:"created_at,readonly"`
+		}
+	)
+
+	db := &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			if strings.HasPrefix(query, "INSERT") {
+				return &testRows{[]string{"created_at", "id"}, [][]driver.Value{{"2016-01-01", int64(7)}}}, nil
+			}
+			return &testRows{[]string{"created_at"}, [][]driver.Value{{"2016-01-02"}}}, nil
+		},
+	}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	users := []user{{Email: "a@email.com"}, {Id: 3, Email: "b@email.com"}}
+	_, err := New(reflect.TypeOf(user{})).SetDialect(PostgreSQL).Save(users).Returning("created_at").GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`INSERT INTO "users" ("email") VALUES ($1) RETURNING "created_at", "id"`,
+		`UPDATE "users" SET "email"=$1 WHERE "id"=$2 RETURNING "created_at"`,
+	}, db.queries)
+	assert.Equal(t, []user{{7, "a@email.com", "2016-01-01"}, {3, "b@email.com", "2016-01-02"}}, users)
+
+	db = &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			if args[0] == "a@email.com" {
+				return &testRows{[]string{"id"}, [][]driver.Value{{int64(8)}}}, nil
+			}
+			return &testRows{[]string{"id"}, [][]driver.Value{{int64(9)}}}, nil
+		},
+	}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	users = []user{{Email: "a@email.com"}, {Email: "b@email.com"}}
+	_, err = New(reflect.TypeOf(user{})).SetDialect(SQLServer).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"INSERT INTO [users] ([email]) OUTPUT INSERTED.[id] VALUES (@p1)", "INSERT INTO [users] ([email]) OUTPUT INSERTED.[id] VALUES (@p1)"}, db.queries)
+	assert.Equal(t, []int64{8, 9}, []int64{users[0].Id, users[1].Id})
+
+	_, err = New(reflect.TypeOf(user{})).SetDialect(MySQL).Save(users).Returning("created_at").GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: Dialect does not support RETURNING!")
+}
+
+func TestRemoveReturning(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+	)
+	db := &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			return &testRows{[]string{"id", "email"}, [][]driver.Value{{int64(1), "a@email.com"}, {int64(2), "b@email.com"}}}, nil
+		},
+	}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	deleted, err := New(reflect.TypeOf(user{})).SetDialect(SQLServer).Delete().Where("id IN (?)", []int{1, 2}).Returning("id", "email").GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DELETE FROM [users] OUTPUT DELETED.[id], DELETED.[email] WHERE id IN (@p1, @p2)"}, db.queries)
+	assert.Equal(t, []user{{1, "a@email.com"}, {2, "b@email.com"}}, deleted)
+
+	_, err = New(reflect.TypeOf(user{})).SetDialect(MySQL).Delete().Returning("id").GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: Dialect does not support RETURNING!")
+}
+
+func TestSaveEntities(t *testing.T) {
+	type (
+		user struct {
+			Id    int64  `json:"id" column:"id"`
+			Email string `json:"email" column:"email"`
+		}
+		account struct {
+			Id int64 `json:"id" column:"id"`
+		}
+	)
+	db := &testDB{}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	qBuilder := New(reflect.TypeOf(user{}))
+
+	saved, err := qBuilder.Save(user{Email: "a@email.com"}).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, user{1, "a@email.com"}, saved)
+
+	single := &user{Email: "b@email.com"}
+	_, err = qBuilder.Save(single).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), single.Id)
+
+	pointers := []*user{{Email: "c@email.com"}, {Email: "d@email.com"}}
+	_, err = qBuilder.Save(&pointers).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, []int64{pointers[0].Id, pointers[1].Id})
+
+	users := []user{{Email: "e@email.com"}}
+	_, err = qBuilder.Save(&users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), users[0].Id)
+
+	_, err = qBuilder.Save([]*user{nil}).GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: Nil user at index 0!")
+
+	_, err = qBuilder.Save(account{}).GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: Can not save goquery.account, expected user, []user or []*user!")
+
+	_, err = qBuilder.Save(1).GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: Can not save int, expected user, []user or []*user!")
+
+	_, err = qBuilder.Save(nil).GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: Can not save <nil>, expected user, []user or []*user!")
+}
+
+func TestUpdateExecute(t *testing.T) {
+	type (
+		user struct {
+			Id     int64  `json:"id" column:"id"`
+			Status string `json:"status" column:"status"`
+		}
+	)
+	db := &testDB{}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	affected, err := New(reflect.TypeOf(user{})).Update().Set("status", "inactive").Where("id IN (?)", []int{1, 2}).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	assert.Equal(t, []string{"UPDATE users SET status = ? WHERE id IN (?, ?)"}, db.queries)
+	assert.Equal(t, [][]driver.Value{{"inactive", int64(1), int64(2)}}, db.args)
+
+	_, err = New(reflect.TypeOf(user{})).Update().Where("id = ?", 1).GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: No columns to update!")
+
+	db.queries, db.args = nil, nil
+	_, err = New(reflect.TypeOf(user{})).SetDialect(MySQL).Update().Set("status", "inactive").OrderBy("id", "ASC").Limit(10).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"UPDATE `users` SET `status` = ? ORDER BY id ASC LIMIT 10"}, db.queries)
+
+	_, err = New(reflect.TypeOf(user{})).SetDialect(MySQL).Delete().Limit(10).Offset(5).GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: ORDER BY, LIMIT and OFFSET are not supported in DELETE by goquery.mysql!")
+
+	_, err = New(reflect.TypeOf(user{})).SetDialect(PostgreSQL).Update().Set("status", "inactive").Limit(10).GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: ORDER BY, LIMIT and OFFSET are not supported in UPDATE by goquery.postgres!")
+
+	_, err = New(reflect.TypeOf(user{})).SetDialect(SQLServer).Delete().OrderBy("id", "ASC").GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: ORDER BY, LIMIT and OFFSET are not supported in DELETE by goquery.sqlserver!")
+}
+
+func TestSaveVersion(t *testing.T) {
+	type (
+		user struct {
+			Id      int64  `json:"id" column:"id"`
+			Email   string `json:"email" column:"email"`
+			Version int64  `json:"version" column:"version,version"`
+		}
+	)
+	db := &testDB{}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	users := []user{{Id: 1, Email: "a@email.com", Version: 3}}
+	_, err := New(reflect.TypeOf(user{})).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"UPDATE users SET email=?, version=? WHERE id=? AND version=?"}, db.queries)
+	assert.Equal(t, [][]driver.Value{{"a@email.com", int64(4), int64(1), int64(3)}}, db.args)
+	assert.Equal(t, int64(4), users[0].Version)
+	assert.Equal(t, 1, db.commits)
+
+	db = &testDB{
+		result: func(query string, args []driver.Value) (driver.Result, error) {
+			if args[2] == int64(2) {
+				return driver.RowsAffected(0), nil
+			}
+			return driver.RowsAffected(1), nil
+		},
+	}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	users = []user{{Id: 1, Email: "a@email.com", Version: 4}, {Id: 2, Email: "b@email.com", Version: 1}}
+	_, err = New(reflect.TypeOf(user{})).Save(users).GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: user [2] was modified concurrently!")
+
+	var conflict *ConflictError
+	assert.True(t, errors.As(err, &conflict))
+	assert.Equal(t, []interface{}{int64(2)}, conflict.Key)
+	assert.Equal(t, []int64{4, 1}, []int64{users[0].Version, users[1].Version})
+	assert.Equal(t, 0, db.commits)
+	assert.Equal(t, 1, db.rollbacks)
+
+	db = &testDB{
+		result: func(query string, args []driver.Value) (driver.Result, error) {
+			if strings.HasPrefix(query, "UPDATE") {
+				return driver.RowsAffected(0), nil
+			}
+			return testResult(1), nil
+		},
+	}
+	conn = openTestDB(db)
+	defer conn.Close()
+
+	users = []user{{Email: "c@email.com"}, {Id: 5, Email: "d@email.com", Version: 3}}
+	_, err = New(reflect.TypeOf(user{})).Save(users).GetQuery().Execute(conn)
+	assert.True(t, errors.As(err, &conflict))
+	assert.Equal(t, []string{"INSERT INTO users (email, version) VALUES (?, ?)", "UPDATE users SET email=?, version=? WHERE id=? AND version=?"}, db.queries)
+	assert.Equal(t, []user{{Email: "c@email.com"}, {Id: 5, Email: "d@email.com", Version: 3}}, users)
+	assert.Equal(t, 1, db.rollbacks)
+
+	type (
+		stamped struct {
+			Id      int64   `json:"id" column:"id"`
+			Version float64 `json:"version" column:"version,version"`
+		}
+	)
+	_, err = New(reflect.TypeOf(stamped{})).Save(stamped{Id: 1}).GetQuery().Execute(conn)
+	assert.EqualError(t, err, "query: Unsupported version type float64 for stamped.Version!")
+}
+
+func TestSoftDeleteExecute(t *testing.T) {
+	type (
+		user struct {
+			Id        int64        `json:"id" column:"id"`
+			Email     string       `json:"email" column:"email"`
+			DeletedAt sql.NullTime `json:"deleted_at" column:"deleted_at,softdelete"`
+		}
+	)
+	db := &testDB{}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	_, err := New(reflect.TypeOf(user{})).Delete(user{Id: 1}).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+
+	affected, err := New(reflect.TypeOf(user{})).Restore(user{Id: 1}).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	_, err = New(reflect.TypeOf(user{})).ForceDelete(user{Id: 1}).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL",
+		"UPDATE users SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL",
+		"DELETE FROM users WHERE id = ?",
+	}, db.queries)
+	assert.Equal(t, [][]driver.Value{{int64(1)}, {int64(1)}, {int64(1)}}, db.args)
+
+	db.queries, db.args = nil, nil
+	_, err = New(reflect.TypeOf(user{})).Save(user{Id: 1, Email: "a@email.com"}).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"UPDATE users SET email=? WHERE id=?"}, db.queries)
+}
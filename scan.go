@@ -0,0 +1,101 @@
+package goquery
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type (
+	ScanPolicy int
+
+	scanner struct {
+		t       reflect.Type
+		columns []string
+		fields  []int
+		extra   int
+	}
+)
+
+const (
+	ScanIgnore ScanPolicy = iota
+	ScanError
+	ScanCollect
+)
+
+func newScanner(b *builder, columns []string) (*scanner, error) {
+	var (
+		t = b.t
+		m = getMetadata(t)
+		s = &scanner{t: t, columns: columns, extra: -1}
+	)
+	if b.unknownColumns == ScanCollect {
+		s.extra = m.extra
+	}
+
+	found := make(map[int]bool)
+	for _, col := range columns {
+		i, ok := m.byName[strings.ToLower(col)]
+		if !ok {
+			i = -1
+			switch b.unknownColumns {
+			case ScanError:
+				return nil, fmt.Errorf("query: Unknown column %q for %s!", col, t.Name())
+			case ScanCollect:
+				if s.extra < 0 {
+					return nil, fmt.Errorf("query: No column:\"*\" map field on %s to collect %q!", t.Name(), col)
+				}
+			}
+		}
+		found[i] = true
+		s.fields = append(s.fields, i)
+	}
+
+	if b.missingColumns == ScanError {
+		_, fields := selectColumns(t, b.columns, "")
+		for _, i := range fields {
+			if !found[i] {
+				return nil, fmt.Errorf("query: Missing column %q for %s!", m.field(i).column, t.Name())
+			}
+		}
+	}
+
+	return s, nil
+}
+
+func (s *scanner) scan(rows *sql.Rows, entity reflect.Value) error {
+	var (
+		dest   = make([]interface{}, len(s.fields))
+		values = make(map[int]*interface{})
+	)
+	for i, field := range s.fields {
+		if field >= 0 {
+			dest[i] = entity.Field(field).Addr().Interface()
+			continue
+		}
+
+		var value interface{}
+		values[i] = &value
+		dest[i] = &value
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	if s.extra < 0 || len(values) == 0 {
+		return nil
+	}
+
+	extra := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		if b, ok := (*value).([]byte); ok {
+			*value = string(b)
+		}
+		extra[s.columns[i]] = *value
+	}
+	entity.Field(s.extra).Set(reflect.ValueOf(extra))
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package goquery
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanByColumnName(t *testing.T) {
+	type (
+		user struct {
+			Email string                 `json:"email" column:"email"`
+			Id    int64                  `json:"id" column:"id"`
+			Name  string                 `json:"name" column:"name"`
+			Extra map[string]interface{} `json:"-" column:"*"`
+		}
+	)
+	db := &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			return &testRows{
+				[]string{"ID", "posts", "email"},
+				[][]driver.Value{{int64(1), int64(3), "a@email.com"}, {int64(2), []byte("0"), "b@email.com"}},
+			}, nil
+		},
+	}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	qBuilder := New(reflect.TypeOf(user{})).From("user_stats").Select("id", "COUNT(p.id) AS posts", "email")
+
+	q := qBuilder.GetQuery()
+	assert.Equal(t, "SELECT id, COUNT(p.id) AS posts, email FROM user_stats", q.GetSQL())
+
+	results, err := q.GetResults(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []user{{Email: "a@email.com", Id: 1}, {Email: "b@email.com", Id: 2}}, results)
+
+	_, err = qBuilder.OnUnknownColumns(ScanError).GetQuery().GetResults(conn)
+	assert.EqualError(t, err, `query: Unknown column "posts" for user!`)
+
+	results, err = qBuilder.OnUnknownColumns(ScanCollect).GetQuery().GetResults(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []user{
+		{Email: "a@email.com", Id: 1, Extra: map[string]interface{}{"posts": int64(3)}},
+		{Email: "b@email.com", Id: 2, Extra: map[string]interface{}{"posts": "0"}},
+	}, results)
+
+	_, err = qBuilder.OnUnknownColumns(ScanIgnore).OnMissingColumns(ScanError).Select().GetQuery().GetResult(conn)
+	assert.EqualError(t, err, `query: Missing column "name" for user!`)
+
+	result, err := qBuilder.Select("id", "email").GetQuery().GetResult(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, user{Email: "a@email.com", Id: 1}, result)
+}
+
+func TestScanCollectWithoutField(t *testing.T) {
+	type (
+		user struct {
+			Id int64 `json:"id" column:"id"`
+		}
+	)
+	db := &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			return &testRows{[]string{"id", "total"}, [][]driver.Value{{int64(1), int64(3)}}}, nil
+		},
+	}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	_, err := New(reflect.TypeOf(user{})).
+		OnUnknownColumns(ScanCollect).
+		Select("id", "total").
+		GetQuery().
+		GetResults(conn)
+
+	assert.EqualError(t, err, `query: No column:"*" map field on user to collect "total"!`)
+}
@@ -0,0 +1,124 @@
+package goquery
+
+import (
+	"reflect"
+	"sync"
+)
+
+type (
+	Tracker struct {
+		mu        sync.Mutex
+		snapshots map[snapshotKey][]interface{}
+	}
+
+	snapshotKey struct {
+		t   reflect.Type
+		key string
+	}
+)
+
+func NewTracker() *Tracker {
+	return &Tracker{snapshots: make(map[snapshotKey][]interface{})}
+}
+
+func (t *Tracker) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.snapshots = make(map[snapshotKey][]interface{})
+}
+
+func (t *Tracker) snapshot(m *metadata, v reflect.Value) {
+	if len(m.pk) == 0 {
+		return
+	}
+
+	values := make([]interface{}, len(m.fields))
+	for i, f := range m.fields {
+		values[i] = copyValue(v.Field(f.index))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.snapshots[trackingKey(m, v)] = values
+}
+
+func (t *Tracker) changes(m *metadata, v reflect.Value, fields []writeField) ([]writeField, bool) {
+	if len(m.pk) == 0 {
+		return fields, false
+	}
+
+	t.mu.Lock()
+	values, ok := t.snapshots[trackingKey(m, v)]
+	t.mu.Unlock()
+	if !ok {
+		return fields, false
+	}
+
+	var changed []writeField
+	for _, f := range fields {
+		for i, mf := range m.fields {
+			if mf.index == f.index && !reflect.DeepEqual(values[i], v.Field(f.index).Interface()) {
+				changed = append(changed, f)
+				break
+			}
+		}
+	}
+
+	return changed, true
+}
+
+func trackingKey(m *metadata, v reflect.Value) snapshotKey {
+	return snapshotKey{v.Type(), keyString(m.keyValues(v))}
+}
+
+func copyValue(v reflect.Value) interface{} {
+	return deepCopy(v).Interface()
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			p := reflect.New(v.Type().Elem())
+			p.Elem().Set(deepCopy(v.Elem()))
+			c.Set(p)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			c.Set(deepCopy(v.Elem()))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			iter := v.MapRange()
+			for iter.Next() {
+				c.SetMapIndex(deepCopy(iter.Key()), deepCopy(iter.Value()))
+			}
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				c.Index(i).Set(deepCopy(v.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Struct:
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+	default:
+		c.Set(v)
+	}
+
+	return c
+}
@@ -0,0 +1,92 @@
+package goquery
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	type (
+		user struct {
+			Id     int64  `json:"id" column:"id"`
+			Email  string `json:"email" column:"email"`
+			Name   string `json:"name" column:"name"`
+			Avatar []byte `json:"avatar" column:"avatar"`
+		}
+	)
+	db := &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			return &testRows{[]string{"id", "email", "name", "avatar"}, [][]driver.Value{
+				{int64(1), "a@email.com", "a", []byte("a")},
+				{int64(2), "b@email.com", "b", []byte("b")},
+			}}, nil
+		},
+	}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	tracker := NewTracker()
+	results, err := New(reflect.TypeOf(user{})).Track(tracker).Select().GetQuery().GetResults(conn)
+	assert.NoError(t, err)
+
+	users := results.([]user)
+	users[0].Name = "aa"
+	users[1].Avatar[0] = 'c'
+	users = append(users, user{Id: 3, Email: "c@email.com"}, user{Email: "d@email.com"})
+
+	db.queries, db.args = nil, nil
+	_, err = New(reflect.TypeOf(user{})).Track(tracker).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"UPDATE users SET name=? WHERE id=?",
+		"UPDATE users SET avatar=? WHERE id=?",
+		"UPDATE users SET email=?, name=?, avatar=? WHERE id=?",
+		"INSERT INTO users (email, name, avatar) VALUES (?, ?, ?)",
+	}, db.queries)
+	assert.Equal(t, []driver.Value{"aa", int64(1)}, db.args[0])
+
+	db.queries, db.args = nil, nil
+	_, err = New(reflect.TypeOf(user{})).Track(tracker).Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Empty(t, db.queries)
+
+	tracker.Clear()
+	_, err = New(reflect.TypeOf(user{})).Track(tracker).Save(users[:1]).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"UPDATE users SET email=?, name=?, avatar=? WHERE id=?"}, db.queries)
+}
+
+func TestTrackerReferences(t *testing.T) {
+	type (
+		profile struct {
+			Bio string
+		}
+		user struct {
+			Id      int64             `json:"id" column:"id"`
+			Nick    *string           `json:"nick" column:"nick"`
+			Tags    map[string]string `json:"tags" column:"tags"`
+			Profile *profile          `json:"profile" column:"profile"`
+		}
+	)
+	nick := "a"
+	u := user{Id: 1, Nick: &nick, Tags: map[string]string{"a": "b"}, Profile: &profile{"bio"}}
+	m := getMetadata(reflect.TypeOf(user{}))
+	fields := m.updateFields()
+
+	tracker := NewTracker()
+	tracker.snapshot(m, reflect.ValueOf(u))
+
+	changed, ok := tracker.changes(m, reflect.ValueOf(u), fields)
+	assert.True(t, ok)
+	assert.Empty(t, changed)
+
+	*u.Nick = "changed"
+	u.Tags["a"] = "c"
+	u.Profile.Bio = "changed"
+
+	changed, _ = tracker.changes(m, reflect.ValueOf(u), fields)
+	assert.Equal(t, fields, changed)
+}
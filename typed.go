@@ -0,0 +1,446 @@
+package goquery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+type (
+	TypedBuilder[T any] struct {
+		builder Builder
+	}
+
+	TypedQuery[T any] struct {
+		query Query
+	}
+
+	NotFoundError struct {
+		Type reflect.Type
+	}
+)
+
+func For[T any]() *TypedBuilder[T] {
+	return &TypedBuilder[T]{New(reflect.TypeOf((*T)(nil)).Elem())}
+}
+
+func (b *TypedBuilder[T]) Select(columns ...string) *TypedBuilder[T] {
+	b.builder.Select(columns...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Count(column string) *TypedBuilder[T] {
+	b.builder.Count(column)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Save(entities []T) *TypedBuilder[T] {
+	b.builder.Save(entities)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) SavePointers(entities []*T) *TypedBuilder[T] {
+	b.builder.Save(entities)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) SaveOne(entity *T) *TypedBuilder[T] {
+	b.builder.Save(entity)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Update() *TypedBuilder[T] {
+	b.builder.Update()
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Set(column string, value interface{}) *TypedBuilder[T] {
+	b.builder.Set(column, value)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) SetExpr(column, expr string, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.SetExpr(column, expr, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) SetMap(values map[string]interface{}) *TypedBuilder[T] {
+	b.builder.SetMap(values)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Find(ids ...interface{}) *TypedBuilder[T] {
+	b.builder.Find(ids...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Delete(entities ...T) *TypedBuilder[T] {
+	b.builder.Delete(toInterfaces(entities)...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) ForceDelete(entities ...T) *TypedBuilder[T] {
+	b.builder.ForceDelete(toInterfaces(entities)...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Restore(entities ...T) *TypedBuilder[T] {
+	b.builder.Restore(toInterfaces(entities)...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) WithTrashed() *TypedBuilder[T] {
+	b.builder.WithTrashed()
+
+	return b
+}
+
+func (b *TypedBuilder[T]) OnlyTrashed() *TypedBuilder[T] {
+	b.builder.OnlyTrashed()
+
+	return b
+}
+
+func (b *TypedBuilder[T]) OnConflict(columns ...string) *TypedBuilder[T] {
+	b.builder.OnConflict(columns...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) DoUpdate(columns ...string) *TypedBuilder[T] {
+	b.builder.DoUpdate(columns...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) DoNothing() *TypedBuilder[T] {
+	b.builder.DoNothing()
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Returning(columns ...string) *TypedBuilder[T] {
+	b.builder.Returning(columns...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Distinct(distinct bool) *TypedBuilder[T] {
+	b.builder.Distinct(distinct)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) From(from string) *TypedBuilder[T] {
+	b.builder.From(from)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Alias(alias string) *TypedBuilder[T] {
+	b.builder.Alias(alias)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Join(table interface{}, alias string, on interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.Join(table, alias, on, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) LeftJoin(table interface{}, alias string, on interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.LeftJoin(table, alias, on, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) RightJoin(table interface{}, alias string, on interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.RightJoin(table, alias, on, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) FullJoin(table interface{}, alias string, on interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.FullJoin(table, alias, on, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) CrossJoin(table interface{}, alias string) *TypedBuilder[T] {
+	b.builder.CrossJoin(table, alias)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Where(where interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.Where(where, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) AndWhere(where interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.AndWhere(where, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) OrWhere(where interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.OrWhere(where, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Having(having interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.Having(having, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) AndHaving(having interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.AndHaving(having, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) OrHaving(having interface{}, parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.OrHaving(having, parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) OrderBy(column, order string) *TypedBuilder[T] {
+	b.builder.OrderBy(column, order)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) AddOrderBy(column, order string) *TypedBuilder[T] {
+	b.builder.AddOrderBy(column, order)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) GroupBy(columns ...string) *TypedBuilder[T] {
+	b.builder.GroupBy(columns...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) AddGroupBy(columns ...string) *TypedBuilder[T] {
+	b.builder.AddGroupBy(columns...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Limit(i int64) *TypedBuilder[T] {
+	b.builder.Limit(i)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Offset(i int64) *TypedBuilder[T] {
+	b.builder.Offset(i)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) SetParameters(parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.SetParameters(parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) AddParameters(parameters ...interface{}) *TypedBuilder[T] {
+	b.builder.AddParameters(parameters...)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) SetNamedParameters(parameters interface{}) *TypedBuilder[T] {
+	b.builder.SetNamedParameters(parameters)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) AddNamedParameters(parameters interface{}) *TypedBuilder[T] {
+	b.builder.AddNamedParameters(parameters)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) SetDialect(dialect Dialect) *TypedBuilder[T] {
+	b.builder.SetDialect(dialect)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) OnUnknownColumns(policy ScanPolicy) *TypedBuilder[T] {
+	b.builder.OnUnknownColumns(policy)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) OnMissingColumns(policy ScanPolicy) *TypedBuilder[T] {
+	b.builder.OnMissingColumns(policy)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) OnEmptySlice(policy EmptySlicePolicy) *TypedBuilder[T] {
+	b.builder.OnEmptySlice(policy)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Track(tracker *Tracker) *TypedBuilder[T] {
+	b.builder.Track(tracker)
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Reset() *TypedBuilder[T] {
+	b.builder.Reset()
+
+	return b
+}
+
+func (b *TypedBuilder[T]) Builder() Builder {
+	return b.builder
+}
+
+func (b *TypedBuilder[T]) GetQuery() *TypedQuery[T] {
+	return &TypedQuery[T]{b.builder.GetQuery()}
+}
+
+func (q *TypedQuery[T]) GetResults(db Executor) ([]T, error) {
+	return q.GetResultsContext(context.Background(), db)
+}
+
+func (q *TypedQuery[T]) GetResult(db Executor) (*T, error) {
+	return q.GetResultContext(context.Background(), db)
+}
+
+func (q *TypedQuery[T]) GetCount(db Executor) (int64, error) {
+	return q.GetCountContext(context.Background(), db)
+}
+
+func (q *TypedQuery[T]) Execute(db Executor) (int64, error) {
+	return q.ExecuteContext(context.Background(), db)
+}
+
+func (q *TypedQuery[T]) ExecuteReturning(db Executor) ([]T, error) {
+	return q.ExecuteReturningContext(context.Background(), db)
+}
+
+func (q *TypedQuery[T]) GetResultsContext(ctx context.Context, db Executor) ([]T, error) {
+	results, err := q.query.GetResultsContext(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return results.([]T), nil
+}
+
+func (q *TypedQuery[T]) GetResultContext(ctx context.Context, db Executor) (*T, error) {
+	result, err := q.query.GetResultContext(ctx, db)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &NotFoundError{reflect.TypeOf((*T)(nil)).Elem()}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entity := result.(T)
+
+	return &entity, nil
+}
+
+func (q *TypedQuery[T]) GetCountContext(ctx context.Context, db Executor) (int64, error) {
+	return q.query.GetCountContext(ctx, db)
+}
+
+func (q *TypedQuery[T]) ExecuteContext(ctx context.Context, db Executor) (int64, error) {
+	result, err := q.query.ExecuteContext(ctx, db)
+	if affected, ok := result.(int64); ok {
+		return affected, err
+	}
+	if result == nil {
+		return 0, err
+	}
+
+	v := reflect.ValueOf(result)
+	if v.Kind() == reflect.Slice {
+		return int64(v.Len()), err
+	}
+
+	return 1, err
+}
+
+func (q *TypedQuery[T]) ExecuteReturningContext(ctx context.Context, db Executor) ([]T, error) {
+	if len(q.query.(*query).builder.returning) == 0 {
+		return nil, errors.New("query: No RETURNING columns!")
+	}
+
+	result, err := q.query.ExecuteContext(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r := result.(type) {
+	case []T:
+		return r, nil
+	case []*T:
+		entities := make([]T, len(r))
+		for i, e := range r {
+			entities[i] = *e
+		}
+
+		return entities, nil
+	case *T:
+		return []T{*r}, nil
+	case T:
+		return []T{r}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (q *TypedQuery[T]) GetSQL() string {
+	return q.query.GetSQL()
+}
+
+func (q *TypedQuery[T]) GetParameters() []interface{} {
+	return q.query.GetParameters()
+}
+
+func toInterfaces[T any](entities []T) []interface{} {
+	values := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		values[i] = entity
+	}
+
+	return values
+}
+
+func (e *NotFoundError) Error() string {
+	return "query: " + e.Type.Name() + " not found!"
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return sql.ErrNoRows
+}
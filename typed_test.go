@@ -0,0 +1,106 @@
+package goquery
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type typedUser struct {
+	Id    int64  `json:"id" column:"id"`
+	Email string `json:"email" column:"email"`
+}
+
+func TestTypedBuilder(t *testing.T) {
+	q := For[typedUser]().
+		Select("id").
+		Where(Eq("email", "a@email.com")).
+		OrderBy("id", "DESC").
+		Limit(1).
+		GetQuery()
+
+	assert.Equal(t, "SELECT id FROM typedusers WHERE email = ? ORDER BY id DESC LIMIT 1", q.GetSQL())
+	assert.Equal(t, []interface{}{"a@email.com"}, q.GetParameters())
+
+	q = For[typedUser]().Find(1).GetQuery()
+	assert.Equal(t, "SELECT id, email FROM typedusers WHERE id = ?", q.GetSQL())
+
+	q = For[typedUser]().Delete(typedUser{Id: 1}, typedUser{Id: 2}).GetQuery()
+	assert.Equal(t, "DELETE FROM typedusers WHERE id IN (?, ?)", q.GetSQL())
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, q.GetParameters())
+
+	q = For[typedUser]().Update().Set("email", "b@email.com").Where(Eq("id", 1)).GetQuery()
+	assert.Equal(t, "UPDATE typedusers SET email = ? WHERE id = ?", q.GetSQL())
+	assert.Equal(t, []interface{}{"b@email.com", 1}, q.GetParameters())
+}
+
+func TestTypedQuery(t *testing.T) {
+	db := &testDB{
+		rows: func(query string, args []driver.Value) (*testRows, error) {
+			if len(args) > 0 && args[0] == int64(3) {
+				return &testRows{[]string{"id", "email"}, nil}, nil
+			}
+			return &testRows{[]string{"id", "email"}, [][]driver.Value{{int64(1), "a@email.com"}, {int64(2), "b@email.com"}}}, nil
+		},
+	}
+	conn := openTestDB(db)
+	defer conn.Close()
+
+	users, err := For[typedUser]().Select().GetQuery().GetResults(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []typedUser{{1, "a@email.com"}, {2, "b@email.com"}}, users)
+
+	user, err := For[typedUser]().Select().Where(Eq("id", 1)).GetQuery().GetResult(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, &typedUser{1, "a@email.com"}, user)
+
+	user, err = For[typedUser]().Select().Where(Eq("id", 3)).GetQuery().GetResult(conn)
+	assert.Nil(t, user)
+	assert.EqualError(t, err, "query: typedUser not found!")
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+
+	var notFound *NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+
+	users, err = For[typedUser]().SetDialect(PostgreSQL).Delete().Returning("id", "email").GetQuery().GetResults(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []typedUser{{1, "a@email.com"}, {2, "b@email.com"}}, users)
+	assert.Equal(t, `DELETE FROM "typedusers" RETURNING "id", "email"`, db.queries[len(db.queries)-1])
+
+	users, err = For[typedUser]().SetDialect(PostgreSQL).Delete(typedUser{Id: 1}, typedUser{Id: 2}).Returning("id", "email").GetQuery().ExecuteReturning(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []typedUser{{1, "a@email.com"}, {2, "b@email.com"}}, users)
+	assert.Equal(t, `DELETE FROM "typedusers" WHERE id IN ($1, $2) RETURNING "id", "email"`, db.queries[len(db.queries)-1])
+
+	_, err = For[typedUser]().Delete().GetQuery().ExecuteReturning(conn)
+	assert.EqualError(t, err, "query: No RETURNING columns!")
+}
+
+func TestTypedSave(t *testing.T) {
+	conn := openTestDB(&testDB{})
+	defer conn.Close()
+
+	users := []typedUser{{Email: "a@email.com"}, {Email: "b@email.com"}}
+	saved, err := For[typedUser]().Save(users).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), saved)
+	assert.Equal(t, []typedUser{{1, "a@email.com"}, {2, "b@email.com"}}, users)
+
+	pointers := []*typedUser{{Email: "c@email.com"}, {Id: 1, Email: "a@email.com"}}
+	_, err = For[typedUser]().SavePointers(pointers).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, &typedUser{3, "c@email.com"}, pointers[0])
+	assert.Equal(t, &typedUser{1, "a@email.com"}, pointers[1])
+
+	user := &typedUser{Email: "d@email.com"}
+	_, err = For[typedUser]().SaveOne(user).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, &typedUser{5, "d@email.com"}, user)
+
+	affected, err := For[typedUser]().Update().Set("email", "e@email.com").Where(Eq("id", 5)).GetQuery().Execute(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+}